@@ -4,7 +4,7 @@
 	"context"
 	"errors"
 	"log/slog"
-	"os"
+	"time"
 
 	"github.com/leighmacdonald/bd/rules"
 	"github.com/leighmacdonald/bd/store"
@@ -13,7 +13,7 @@
 
 // unMark will unmark & remove a player from your local list. This *will not* unmark players from any
 // other list sources. If you want to not kick someone on a 3rd party list, you can instead whitelist the player.
-func unMark(ctx context.Context, re *rules.Engine, db store.Querier, _ *gameState, sid64 steamid.SteamID) (int, error) {
+func unMark(ctx context.Context, re *rules.Engine, db store.Querier, local *localLists, sid64 steamid.SteamID) (int, error) {
 	player, errPlayer := loadPlayerOrCreate(ctx, db, sid64)
 	if errPlayer != nil {
 		return 0, errPlayer
@@ -23,6 +23,8 @@ func unMark(ctx context.Context, re *rules.Engine, db store.Querier, _ *gameStat
 		return 0, errNotMarked
 	}
 
+	local.savePlayers()
+
 	var valid []rules.MatchResult //nolint:prealloc
 
 	for _, m := range player.Matches {
@@ -38,8 +40,9 @@ func unMark(ctx context.Context, re *rules.Engine, db store.Querier, _ *gameStat
 	return len(valid), nil
 }
 
-// mark will add a new entry in your local player list.
-func mark(ctx context.Context, sm *settingsManager, db store.Querier, state *gameState, re *rules.Engine, sid64 steamid.SteamID, attrs []string) error {
+// mark will add a new entry in your local player list. Attributes expire at the expires time if set, otherwise
+// they use the default ttl for the attribute from the settings.
+func mark(ctx context.Context, sm *settingsManager, db store.Querier, state *gameState, re *rules.Engine, local *localLists, sid64 steamid.SteamID, attrs []string, expires time.Time) error {
 	player, errPlayer := state.players.bySteamID(sid64)
 	if errPlayer != nil {
 		if !errors.Is(errPlayer, errPlayerNotFound) {
@@ -52,25 +55,24 @@ func mark(ctx context.Context, sm *settingsManager, db store.Querier, state *gam
 		player = created
 	}
 
+	var attributeExpiry map[string]time.Time
+	if expires.IsZero() {
+		settings := sm.Settings()
+		attributeExpiry = settings.MarkExpiry(attrs)
+	}
+
 	if errMark := re.Mark(rules.MarkOpts{
-		SteamID:    sid64,
-		Attributes: attrs,
-		Name:       player.Personaname,
-		Proof:      []string{},
+		SteamID:         sid64,
+		Attributes:      attrs,
+		Name:            player.Personaname,
+		Proof:           []string{},
+		Expires:         expires,
+		AttributeExpiry: attributeExpiry,
 	}); errMark != nil {
 		return errors.Join(errMark, errMark)
 	}
 
-	outputFile, errOf := os.OpenFile(sm.LocalPlayerListPath(), os.O_RDWR|os.O_CREATE|os.O_TRUNC, os.ModePerm)
-	if errOf != nil {
-		return errors.Join(errOf, errPlayerListOpen)
-	}
-
-	defer LogClose(outputFile)
-
-	if errExport := re.ExportPlayers(rules.LocalRuleName, outputFile); errExport != nil {
-		slog.Error("Failed to save updated player list", errAttr(errExport))
-	}
+	local.savePlayers()
 
 	return nil
 }
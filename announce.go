@@ -13,6 +13,9 @@
 	"github.com/leighmacdonald/steamid/v4/steamid"
 )
 
+// announceQueueSize is the number of announcements that can be waiting for the overwatch before new ones are dropped.
+const announceQueueSize = 32
+
 type kickRequest struct {
 	steamID steamid.SteamID
 	reason  KickReason
@@ -40,6 +43,8 @@ func (bb overwatch) start(ctx context.Context) {
 		select {
 		case <-timer.C:
 			bb.update()
+		case evt := <-bb.state.announceChan:
+			bb.announceMatch(ctx, evt.player, evt.matches)
 		case <-ctx.Done():
 			return
 		}
@@ -65,8 +70,10 @@ func (bb overwatch) nextKickTarget() (PlayerState, bool) {
 		validTargets = append(validTargets, player)
 	}
 
+	threshold := bb.settings.Settings().KickScoreThreshold
+
 	for _, player := range bb.state.players.current() {
-		if len(player.Matches) > 0 && !player.Whitelist {
+		if len(player.Matches) > 0 && !player.Whitelist && player.Suspicion.Total >= threshold {
 			validTargets = append(validTargets, player)
 		}
 	}
@@ -83,11 +90,12 @@ func (bb overwatch) nextKickTarget() (PlayerState, bool) {
 	return validTargets[0], true
 }
 
-// announceMatch handles announcing after a match is triggered against a player.
+// announceMatch handles announcing after a match is triggered against a player. Players whose suspicion score is
+// below the announce threshold are not announced.
 func (bb overwatch) announceMatch(ctx context.Context, player PlayerState, matches []rules.MatchResult) {
 	settings := bb.settings.Settings()
 
-	if len(matches) == 0 {
+	if len(matches) == 0 || player.Suspicion.Total < settings.AnnounceScoreThreshold {
 		return
 	}
 
@@ -105,9 +113,9 @@ func (bb overwatch) announceMatch(ctx context.Context, player PlayerState, match
 				slog.String("origin", match.Origin))
 		}
 
-		player.AnnouncedGeneralLast = time.Now()
-
-		bb.state.players.update(player)
+		bb.setAnnounced(player.SteamID, func(current *PlayerState) {
+			current.AnnouncedGeneralLast = time.Now()
+		})
 	}
 
 	if player.Whitelist {
@@ -124,10 +132,23 @@ func (bb overwatch) announceMatch(ctx context.Context, player PlayerState, match
 			}
 		}
 
-		player.AnnouncedPartyLast = time.Now()
+		bb.setAnnounced(player.SteamID, func(current *PlayerState) {
+			current.AnnouncedPartyLast = time.Now()
+		})
+	}
+}
 
-		bb.state.players.update(player)
+// setAnnounced updates the announcement times on the current state of the player. The player being announced is
+// a snapshot which may be out of date by the time the announcement is sent, so it must not be written back.
+func (bb overwatch) setAnnounced(steamID steamid.SteamID, update func(current *PlayerState)) {
+	current, errPlayer := bb.state.players.bySteamID(steamID)
+	if errPlayer != nil {
+		return
 	}
+
+	update(&current)
+
+	bb.state.players.update(current)
 }
 
 // sendChat is used to send chat messages to the various chat interfaces in game: say|say_team|say_party.
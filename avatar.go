@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/leighmacdonald/bd/rules"
+)
+
+const avatarCDNURL = "https://avatars.cloudflare.steamstatic.com"
+
+// avatarLoader downloads player avatars so that they can be matched against the loaded avatar rules. Downloaded
+// avatars are stored in the local cache to avoid fetching the same avatar more than once.
+type avatarLoader struct {
+	cache   Cache
+	re      *rules.Engine
+	client  *http.Client
+	baseURL string
+}
+
+func newAvatarLoader(cache Cache, re *rules.Engine, baseURL string) avatarLoader {
+	return avatarLoader{
+		cache:   cache,
+		re:      re,
+		client:  &http.Client{},
+		baseURL: baseURL,
+	}
+}
+
+// fetch returns the full size avatar image for the hash, using the cached copy when available.
+func (a avatarLoader) fetch(ctx context.Context, hash string) ([]byte, error) {
+	// Ensure the hash is in the expected format before it's used to build urls and cache paths
+	if !rules.ValidAvatarHash(hash) {
+		return nil, fmt.Errorf("%w: %s", errAvatarHash, hash)
+	}
+
+	key := hash + ".jpg"
+
+	var cached bytes.Buffer
+	if errCache := a.cache.Get(TypeAvatars, key, &cached); errCache == nil && cached.Len() > 0 {
+		return cached.Bytes(), nil
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, DurationWebRequestTimeout)
+	defer cancel()
+
+	req, errReq := http.NewRequestWithContext(timeout, http.MethodGet, fmt.Sprintf("%s/%s_full.jpg", a.baseURL, hash), nil)
+	if errReq != nil {
+		return nil, errors.Join(errReq, errCreateRequest)
+	}
+
+	resp, errResp := a.client.Do(req)
+	if errResp != nil {
+		return nil, errors.Join(errResp, errPerformRequest)
+	}
+
+	defer LogClose(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", errFetchAvatar, resp.StatusCode)
+	}
+
+	body, errBody := io.ReadAll(resp.Body)
+	if errBody != nil {
+		return nil, errors.Join(errBody, errReadResponse)
+	}
+
+	if errSet := a.cache.Set(TypeAvatars, key, bytes.NewReader(body)); errSet != nil {
+		slog.Error("Failed to cache avatar", errAttr(errSet), slog.String("hash", hash))
+	}
+
+	return body, nil
+}
+
+// match downloads the avatar and checks it against all the loaded avatar rules. The perceptual hash of the avatar
+// is also returned so that it can be used by rules which match on the players avatar along with other triggers. It's
+// empty if the image could not be decoded.
+func (a avatarLoader) match(ctx context.Context, hash string) ([]rules.MatchResult, string, error) {
+	avatar, errFetch := a.fetch(ctx, hash)
+	if errFetch != nil {
+		return nil, "", errFetch
+	}
+
+	perceptualHash, errPerceptual := rules.PerceptualHash(avatar)
+	if errPerceptual != nil {
+		slog.Debug("Failed to compute perceptual avatar hash", errAttr(errPerceptual), slog.String("hash", hash))
+	}
+
+	return a.re.MatchAvatar(avatar), perceptualHash, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/jpeg"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/bd/rules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAvatarLoader(t *testing.T) {
+	var (
+		buf      bytes.Buffer
+		requests atomic.Int32
+		ctx      = context.Background()
+	)
+
+	require.NoError(t, jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 184, 184)), &jpeg.Options{Quality: 10}))
+
+	avatar := buf.Bytes()
+	hash := rules.HashAvatar(avatar)
+
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		if r.URL.Path != "/"+hash+"_full.jpg" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		_, _ = w.Write(avatar)
+	}))
+	defer cdn.Close()
+
+	cache, errCache := NewCache(t.TempDir(), time.Hour)
+	require.NoError(t, errCache)
+
+	engine := rules.New()
+	engine.UserRuleList().RegisterAvatarMatcher(
+		rules.NewAvatarMatcher(rules.LocalRuleName, "bot avatar", rules.AvatarMatchExact, []string{"bot"}, hash))
+
+	loader := newAvatarLoader(cache, engine, cdn.URL)
+
+	matches, perceptualHash, errMatch := loader.match(ctx, hash)
+	require.NoError(t, errMatch)
+	require.Len(t, perceptualHash, 16)
+	require.Len(t, matches, 1)
+	require.Equal(t, hash, matches[0].Pattern)
+	require.True(t, matches[0].HasAttr("bot"))
+	require.EqualValues(t, 1, requests.Load())
+
+	// The second lookup should be served from the cache
+	cachedMatches, cachedPerceptualHash, errCached := loader.match(ctx, hash)
+	require.NoError(t, errCached)
+	require.Equal(t, matches, cachedMatches)
+	require.Equal(t, perceptualHash, cachedPerceptualHash)
+	require.EqualValues(t, 1, requests.Load())
+
+	_, _, errMissing := loader.match(ctx, "fef49e7fa7e1997310d705b2a6158ff8dc1cdfea")
+	require.ErrorIs(t, errMissing, errFetchAvatar)
+
+	_, _, errInvalid := loader.match(ctx, "../../settings")
+	require.ErrorIs(t, errInvalid, errAvatarHash)
+	require.EqualValues(t, 2, requests.Load())
+}
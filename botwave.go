@@ -0,0 +1,344 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/leighmacdonald/bd/rules"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/leighmacdonald/steamweb/v2"
+)
+
+const (
+	// botWaveOrigin is used as the origin of all matches produced by the bot wave detector.
+	botWaveOrigin = "Bot Wave Detector"
+	// botWaveAttribute is the attribute given to the members of a detected bot wave.
+	botWaveAttribute = "suspicious/bot_wave"
+	// botWaveJoinRetention is how long connect events are remembered.
+	botWaveJoinRetention = time.Minute * 10
+
+	// The points each heuristic contributes to the score of a group, for a total of 100.
+	botWavePointsFresh   = 35.0
+	botWavePointsAge     = 20.0
+	botWavePointsPrivate = 20.0
+	botWavePointsFriends = 25.0
+)
+
+// BotWaveConfig defines how groups of players joining together are detected and scored.
+type BotWaveConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// JoinWindow is the number of seconds between consecutive joins for the players to be considered a group.
+	JoinWindow int `yaml:"join_window" json:"join_window"`
+	// MinGroupSize is the smallest number of players considered a wave.
+	MinGroupSize int `yaml:"min_group_size" json:"min_group_size"`
+	// FreshAccountDays is the age in days under which an account is considered fresh.
+	FreshAccountDays int `yaml:"fresh_account_days" json:"fresh_account_days"`
+	// AccountAgeSpread is the number of days within which all the members accounts must have been created to be
+	// considered clustered.
+	AccountAgeSpread int `yaml:"account_age_spread" json:"account_age_spread"`
+	// ScoreThreshold is the 0-100 score at which a group is matched.
+	ScoreThreshold float64 `yaml:"score_threshold" json:"score_threshold"`
+}
+
+func newBotWaveConfig() BotWaveConfig {
+	return BotWaveConfig{
+		Enabled:          true,
+		JoinWindow:       30,
+		MinGroupSize:     3,
+		FreshAccountDays: 30,
+		AccountAgeSpread: 7,
+		ScoreThreshold:   60,
+	}
+}
+
+// waveMember is a player along with the information used to detect which wave, if any, they are part of.
+type waveMember struct {
+	player   PlayerState
+	joinedAt time.Time
+	friends  []int64
+}
+
+// botWave is a scored group of players who joined together.
+type botWave struct {
+	members []waveMember
+	fresh   int
+	private int
+	linked  int
+	// clustered is true when all the known account creation dates fall within the configured spread
+	clustered bool
+	score     float64
+}
+
+// botWaveDetector tracks when players connect so that bursts of joins can be scored once their profile data has
+// been loaded.
+type botWaveDetector struct {
+	// connects holds the connect times seen in the console, which only include the players name, until status
+	// resolves the name to a steam id
+	connects map[string]time.Time
+	// joins holds the console connect times of the players that status has resolved
+	joins map[steamid.SteamID]time.Time
+	// estimated holds the connect times estimated from the connection duration reported by status
+	estimated map[steamid.SteamID]time.Time
+}
+
+func newBotWaveDetector() *botWaveDetector {
+	return &botWaveDetector{
+		connects:  map[string]time.Time{},
+		joins:     map[steamid.SteamID]time.Time{},
+		estimated: map[steamid.SteamID]time.Time{},
+	}
+}
+
+// pruneJoins removes any join times older than botWaveJoinRetention.
+func pruneJoins[K comparable](joins map[K]time.Time, now time.Time) {
+	for key, joinedAt := range joins {
+		if now.Sub(joinedAt) > botWaveJoinRetention {
+			delete(joins, key)
+		}
+	}
+}
+
+// connected records the player as connecting now. The timestamps of console lines are in the local time of the game
+// but parsed as UTC, so the time the event is received is used instead to stay in the same time base as the
+// estimates recorded by seen.
+func (d *botWaveDetector) connected(name string) {
+	now := time.Now()
+
+	pruneJoins(d.connects, now)
+
+	d.connects[name] = now
+}
+
+// seen resolves any console connect for the players name to their steam id, and records the connect time estimated
+// from the players current connection duration. Estimates are refreshed on every status update so current players
+// are retained.
+func (d *botWaveDetector) seen(steamID steamid.SteamID, name string, connected time.Duration) {
+	now := time.Now()
+
+	pruneJoins(d.connects, now)
+	pruneJoins(d.joins, now)
+	pruneJoins(d.estimated, now)
+
+	if joinedAt, found := d.connects[name]; found {
+		d.joins[steamID] = joinedAt
+		delete(d.connects, name)
+	}
+
+	d.estimated[steamID] = now.Add(-connected)
+}
+
+// joinedAt returns the time the player connected. When no connect event was seen, such as when we joined after
+// them, it's estimated from the connection duration reported by status.
+func (d *botWaveDetector) joinedAt(player PlayerState) time.Time {
+	if joinedAt, found := d.joins[player.SteamID]; found {
+		return joinedAt
+	}
+
+	return d.estimated[player.SteamID]
+}
+
+// candidates returns the connected players which may be part of a wave. We, our friends, whitelisted players and
+// players whose profile data has not been loaded yet are never included. Private profiles have their creation date
+// set to the unix epoch once loaded, so only players that have never been loaded have a zero creation date.
+func (d *botWaveDetector) candidates(players []PlayerState, ourSID steamid.SteamID) []waveMember {
+	var members []waveMember
+
+	for _, player := range players {
+		if !player.IsConnected || player.SteamID == ourSID || player.OurFriend || player.Whitelist ||
+			player.AccountCreatedOn.IsZero() {
+			continue
+		}
+
+		joinedAt := d.joinedAt(player)
+		if joinedAt.IsZero() {
+			continue
+		}
+
+		members = append(members, waveMember{player: player, joinedAt: joinedAt})
+	}
+
+	return members
+}
+
+// clusterJoins groups the members by join time. Each member joined within the window of the previous member of
+// their group. Groups smaller than minSize are discarded.
+func clusterJoins(members []waveMember, window time.Duration, minSize int) [][]waveMember {
+	sorted := slices.Clone(members)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].joinedAt.Before(sorted[j].joinedAt)
+	})
+
+	var (
+		groups  [][]waveMember
+		current []waveMember
+	)
+
+	for _, member := range sorted {
+		if len(current) > 0 && member.joinedAt.Sub(current[len(current)-1].joinedAt) > window {
+			if len(current) >= minSize {
+				groups = append(groups, current)
+			}
+
+			current = nil
+		}
+
+		current = append(current, member)
+	}
+
+	if len(current) >= minSize {
+		groups = append(groups, current)
+	}
+
+	return groups
+}
+
+// scoreBotWave scores the group based on the share of fresh accounts, private profiles and members who are friends
+// with, or share a friend with, another member, along with how closely together the accounts were created.
+func scoreBotWave(members []waveMember, config BotWaveConfig, now time.Time) botWave {
+	wave := botWave{members: members}
+
+	var created []time.Time
+
+	for index, member := range members {
+		createdOn := member.player.AccountCreatedOn
+		if createdOn.IsZero() || createdOn.Unix() <= 0 {
+			// Private profiles do not expose their creation date
+			wave.fresh++
+		} else {
+			created = append(created, createdOn)
+
+			if now.Sub(createdOn) < time.Hour*24*time.Duration(config.FreshAccountDays) {
+				wave.fresh++
+			}
+		}
+
+		if member.player.Visibility != int64(steamweb.VisibilityPublic) {
+			wave.private++
+		}
+
+		for otherIndex, other := range members {
+			if index != otherIndex && membersLinked(member, other) {
+				wave.linked++
+
+				break
+			}
+		}
+	}
+
+	if len(created) >= 2 {
+		oldest, newest := slices.MinFunc(created, compareTime), slices.MaxFunc(created, compareTime)
+		wave.clustered = newest.Sub(oldest) <= time.Hour*24*time.Duration(config.AccountAgeSpread)
+	}
+
+	size := float64(len(members))
+	wave.score = botWavePointsFresh*float64(wave.fresh)/size +
+		botWavePointsPrivate*float64(wave.private)/size +
+		botWavePointsFriends*float64(wave.linked)/size
+
+	if wave.clustered {
+		wave.score += botWavePointsAge
+	}
+
+	return wave
+}
+
+func compareTime(a time.Time, b time.Time) int {
+	return a.Compare(b)
+}
+
+// membersLinked returns true if either member is friends with the other, or they share a friend.
+func membersLinked(a waveMember, b waveMember) bool {
+	if slices.Contains(a.friends, b.player.SteamID.Int64()) || slices.Contains(b.friends, a.player.SteamID.Int64()) {
+		return true
+	}
+
+	for _, friend := range a.friends {
+		if slices.Contains(b.friends, friend) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// match creates the group match given to every member of the wave. The pattern lists all the members.
+func (w botWave) match() rules.MatchResult {
+	members := make([]string, len(w.members))
+	for index, member := range w.members {
+		members[index] = member.player.SteamID.String()
+	}
+
+	size := len(w.members)
+
+	return rules.MatchResult{
+		Origin:      botWaveOrigin,
+		Attributes:  []string{botWaveAttribute},
+		MatcherType: "bot_wave",
+		Pattern:     strings.Join(members, ","),
+		Description: fmt.Sprintf("Joined with %d players: %d/%d fresh, %d/%d private, %d/%d linked by friends, "+
+			"clustered creation: %t (score %.0f)",
+			size-1, w.fresh, size, w.private, size, w.linked, size, w.clustered, w.score),
+		Severity: rules.SeverityMedium,
+		Weight:   rules.SeverityMedium.Weight(),
+	}
+}
+
+// detectBotWave scores each group of players who joined together, adding a match listing the group to every
+// member of any group at or above the score threshold. Members already matched to a wave are not matched again.
+func (s *gameState) detectBotWave(ctx context.Context) {
+	config := s.settings.Settings().BotWaveDetection
+	if !config.Enabled {
+		return
+	}
+
+	candidates := s.waves.candidates(s.players.all(), s.settings.Settings().SteamID)
+
+	for _, group := range clusterJoins(candidates, time.Second*time.Duration(config.JoinWindow), config.MinGroupSize) {
+		for index := range group {
+			group[index].friends = s.storedFriends(ctx, group[index].player.SteamID)
+		}
+
+		wave := scoreBotWave(group, config, time.Now())
+		if wave.score < config.ScoreThreshold {
+			continue
+		}
+
+		match := wave.match()
+
+		for _, member := range group {
+			player, errPlayer := s.players.bySteamID(member.player.SteamID)
+			if errPlayer != nil || slices.ContainsFunc(player.Matches, func(known rules.MatchResult) bool {
+				return known.Origin == botWaveOrigin
+			}) {
+				continue
+			}
+
+			slog.Info("Detected bot wave member", sidAttr(player.SteamID), slog.String("wave", match.Pattern),
+				slog.Float64("score", wave.score))
+
+			s.players.update(s.applyRuleMatches(player, []rules.MatchResult{match}, match.Description))
+		}
+	}
+}
+
+// storedFriends returns the steam ids of the players friends from the player_friends table.
+func (s *gameState) storedFriends(ctx context.Context, steamID steamid.SteamID) []int64 {
+	rows, errFriends := s.db.Friends(ctx, steamID.Int64())
+	if errFriends != nil {
+		slog.Error("Failed to load player friends", errAttr(errFriends), sidAttr(steamID))
+
+		return nil
+	}
+
+	friends := make([]int64, len(rows))
+	for index, row := range rows {
+		friends[index] = row.SteamIDFriend
+	}
+
+	return friends
+}
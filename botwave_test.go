@@ -0,0 +1,110 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/leighmacdonald/steamweb/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBotWave(t *testing.T) {
+	var (
+		now    = time.Now()
+		config = newBotWaveConfig()
+		fresh  = now.AddDate(0, 0, -2)
+		old    = now.AddDate(-8, 0, 0)
+	)
+
+	member := func(sid int64, joined time.Duration, created time.Time, visibility steamweb.VisibilityState, friends ...int64) waveMember {
+		return waveMember{
+			player: PlayerState{
+				SteamID:          steamid.New(sid),
+				AccountCreatedOn: created,
+				Visibility:       int64(visibility),
+			},
+			joinedAt: now.Add(joined),
+			friends:  friends,
+		}
+	}
+
+	var (
+		botA    = member(76561198000000001, 0, fresh, steamweb.VisibilityPrivate, 76561198000000002)
+		botB    = member(76561198000000002, time.Second*5, fresh.Add(time.Hour), steamweb.VisibilityPrivate, 76561198000000009)
+		botC    = member(76561198000000003, time.Second*20, time.Unix(0, 0), steamweb.VisibilityPrivate, 76561198000000009)
+		regular = member(76561197970669109, time.Minute*5, old, steamweb.VisibilityPublic)
+		late    = member(76561197992870439, time.Minute*6, old, steamweb.VisibilityPublic)
+	)
+
+	groups := clusterJoins([]waveMember{regular, botC, late, botA, botB}, time.Second*time.Duration(config.JoinWindow),
+		config.MinGroupSize)
+	require.Len(t, groups, 1)
+	require.Equal(t, []waveMember{botA, botB, botC}, groups[0])
+
+	wave := scoreBotWave(groups[0], config, now)
+	require.Equal(t, 3, wave.fresh)
+	require.Equal(t, 3, wave.private)
+	require.Equal(t, 3, wave.linked)
+	require.True(t, wave.clustered)
+	require.InDelta(t, 100.0, wave.score, 0.001)
+
+	match := wave.match()
+	require.Equal(t, botWaveOrigin, match.Origin)
+	require.Equal(t, "76561198000000001,76561198000000002,76561198000000003", match.Pattern)
+
+	// A group of old, public and unrelated accounts that happened to join together
+	normal := scoreBotWave([]waveMember{regular, late, member(76561198004429398, time.Minute*6, old.AddDate(2, 0, 0),
+		steamweb.VisibilityPublic)}, config, now)
+	require.Less(t, normal.score, config.ScoreThreshold)
+}
+
+func TestBotWaveJoins(t *testing.T) {
+	var (
+		detector = newBotWaveDetector()
+		botSID   = steamid.New(76561198000000001)
+		humanSID = steamid.New(76561197970669109)
+		start    = time.Now()
+	)
+
+	detector.joins[steamid.New(76561197992870439)] = start.Add(-botWaveJoinRetention - time.Minute)
+
+	detector.connected("bot")
+	detector.seen(humanSID, "human", time.Minute)
+
+	// The console connect is only used once status resolves the name to a steam id
+	require.True(t, detector.joinedAt(PlayerState{SteamID: botSID, Personaname: "bot"}).IsZero())
+	detector.seen(botSID, "bot", time.Minute*5)
+
+	// Console joins and status estimates share the same time base
+	require.WithinDuration(t, start, detector.joinedAt(PlayerState{SteamID: botSID, Personaname: "bot"}), time.Second)
+	require.WithinDuration(t, start.Add(-time.Minute), detector.joinedAt(PlayerState{SteamID: humanSID, Personaname: "human"}), time.Second)
+
+	// Changing name after connecting does not lose the join
+	require.WithinDuration(t, start, detector.joinedAt(PlayerState{SteamID: botSID, Personaname: "renamed"}), time.Second)
+
+	require.Len(t, detector.joins, 1, "Joins older than the retention should be pruned")
+}
+
+func TestBotWaveCandidates(t *testing.T) {
+	var (
+		detector = newBotWaveDetector()
+		ourSID   = steamid.New(76561197961279983)
+		loaded   = newPlayer(steamid.New(76561198000000001), "loaded")
+		private  = newPlayer(steamid.New(76561198000000002), "private")
+		unloaded = newPlayer(steamid.New(76561198000000003), "unloaded")
+	)
+
+	loaded.AccountCreatedOn = time.Now().AddDate(0, 0, -2)
+	private.AccountCreatedOn = time.Unix(0, 0)
+
+	for _, player := range []*PlayerState{&loaded, &private, &unloaded} {
+		player.IsConnected = true
+		detector.seen(player.SteamID, player.Personaname, time.Minute)
+	}
+
+	members := detector.candidates([]PlayerState{loaded, private, unloaded}, ourSID)
+	require.Len(t, members, 2)
+	require.Equal(t, loaded.SteamID, members[0].player.SteamID)
+	require.Equal(t, private.SteamID, members[1].player.SteamID)
+}
@@ -36,6 +36,7 @@ type FsCache struct {
 
 const (
 	TypeLists Type = iota
+	TypeAvatars
 )
 
 // NewCache creates a new local storage backed cache for avatars and player lists.
@@ -50,7 +51,7 @@ func NewCache(rootDir string, maxAge time.Duration) (FsCache, error) {
 
 // init creates the directory structure used to store locally cached files.
 func (cache FsCache) init() error {
-	for _, p := range []Type{TypeLists} {
+	for _, p := range []Type{TypeLists, TypeAvatars} {
 		if errMkDir := os.MkdirAll(cache.getPath(p, ""), 0o770); errMkDir != nil {
 			return errors.Join(errMkDir, errCacheSetup)
 		}
@@ -63,6 +64,8 @@ func (cache FsCache) getPath(cacheType Type, key string) string {
 	switch cacheType {
 	case TypeLists:
 		return filepath.Join(cache.rootPath, "lists", key)
+	case TypeAvatars:
+		return filepath.Join(cache.rootPath, "avatars", key)
 	default:
 		cache.logger.Error("Got unknown cache type", slog.Int("type", int(cacheType)))
 
@@ -76,7 +79,7 @@ func (cache FsCache) Set(ct Type, key string, value io.Reader) error {
 		return errors.Join(errMkdir, errCreateCacheDir)
 	}
 
-	openFile, errOf := os.OpenFile(fullPath, os.O_WRONLY|os.O_CREATE, 0o660)
+	openFile, errOf := os.OpenFile(fullPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o660)
 	if errOf != nil {
 		return errors.Join(errOf, errOpenCacheFile)
 	}
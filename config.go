@@ -114,7 +114,7 @@ func getUserLaunchArgs(steamRoot string, steamID steamid.SteamID) ([]string, err
 	return launchOpts, nil
 }
 
-func getLaunchArgs(rconPass string, rconPort uint16, steamRoot string, steamID steamid.SteamID, udpEnabled bool, udpAddr string) ([]string, error) {
+func getLaunchArgs(rconPass string, rconPort uint16, steamRoot string, steamID steamid.SteamID, udpEnabled bool, udpAddr string, udpSecret int32) ([]string, error) {
 	userArgs, errUserArgs := getUserLaunchArgs(steamRoot, steamID)
 	if errUserArgs != nil {
 		return nil, errors.Join(errUserArgs, errSteamLaunchArgs)
@@ -138,7 +138,7 @@ func getLaunchArgs(rconPass string, rconPort uint16, steamRoot string, steamID s
 	}
 
 	if udpEnabled {
-		bdArgs = append(bdArgs, "+logaddress_add", udpAddr)
+		bdArgs = append(bdArgs, "+sv_logsecret", fmt.Sprintf("%d", udpSecret), "+logaddress_add", udpAddr)
 	}
 
 	var full []string //nolint:prealloc
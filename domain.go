@@ -30,12 +30,12 @@
 	errDataSourceAPIAddr  = errors.New("api data source url invalid")
 	errDataSourceLocal    = errors.New("failed to load local data source")
 
-	errPlayerListOpen    = errors.New("failed to open player list")
 	errPathNotExist      = errors.New("path does not exist")
 	errCreateMessage     = errors.New("failed to create user message")
 	errCreatePlayer      = errors.New("failed to create new player")
 	errSaveMessage       = errors.New("failed to save user message")
 	errGetNames          = errors.New("failed to load name history")
+	errGetMessages       = errors.New("failed to load message history")
 	errSaveNames         = errors.New("failed to save name history")
 	errGetPlayer         = errors.New("failed to load player record")
 	errSavePlayer        = errors.New("failed to save player to database")
@@ -73,6 +73,18 @@
 	errTempDir           = errors.New("failed to create temp dir")
 	errSettingsBDAPIAddr = errors.New("bd-api address invalid")
 	errResolveAddr       = errors.New("failed to resolve address")
+	errLogPacketHeader   = errors.New("invalid log packet header")
+	errLogPacketType     = errors.New("unsupported log packet type")
+	errLogPacketMarker   = errors.New("log packet missing line marker")
+	errLogPacketSecret   = errors.New("log packet secret does not match")
+	errLogPacketSource   = errors.New("plain log packet source not allowed")
+	errLogSource         = errors.New("invalid log source address")
+	errReplayOpen        = errors.New("failed to open replay log")
+	errAvatarHash        = errors.New("invalid avatar hash")
+	errFetchAvatar       = errors.New("failed to fetch avatar")
+	errLocalListWrite    = errors.New("failed to write local list")
+	errLocalListBackup   = errors.New("failed to backup local list")
+	errLocalListRecover  = errors.New("failed to recover local list from backups")
 )
 
 const (
@@ -85,6 +97,8 @@
 	DurationWebRequestTimeout    = time.Second * 5
 	DurationRCONRequestTimeout   = time.Second * 2
 	DurationProcessTimeout       = time.Second * 3
+	DurationPruneExpiredMarks    = time.Hour
+	DurationListRetry            = time.Minute * 5
 )
 
 type EventType int
@@ -101,6 +115,9 @@
 	EvtTags
 	EvtAddress
 	EvtLobby
+	EvtSuicide
+	EvtTeam
+	EvtClass
 )
 
 type KickReason string
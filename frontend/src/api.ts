@@ -60,10 +60,43 @@ export enum Team {
     RED
 }
 
+export enum PlayerClass {
+    UNKNOWN,
+    SCOUT,
+    SNIPER,
+    SOLDIER,
+    DEMOMAN,
+    MEDIC,
+    HEAVY,
+    PYRO,
+    SPY,
+    ENGINEER
+}
+
 export interface Match {
     origin: string;
     attributes: string[];
     matcher_type: string;
+    pattern: string;
+    description: string;
+    similarity: number;
+    mark: string[] | null;
+    transient_mark: string[] | null;
+    severity: severity;
+    weight: number;
+}
+
+export type severity = 'low' | 'medium' | 'high' | 'critical';
+
+export interface SuspicionComponent {
+    source: string;
+    description: string;
+    points: number;
+}
+
+export interface SuspicionScore {
+    total: number;
+    breakdown: SuspicionComponent[];
 }
 
 export interface Server {
@@ -93,6 +126,7 @@ export interface Player {
     real_name: string;
     account_created_on: Date;
     avatar_hash: string;
+    avatar_perceptual_hash: string;
     community_banned: boolean;
     game_bans: number;
     vac_bans: number;
@@ -107,6 +141,7 @@ export interface Player {
     updated_on: Date;
     economy_ban: boolean;
     team: Team;
+    class: PlayerClass;
     connected: number;
     map_time: number;
     user_id: number;
@@ -118,11 +153,21 @@ export interface Player {
     valid: boolean;
     deaths: number;
     kills: number;
+    assists: number;
+    suicides: number;
+    weapons: Record<string, WeaponStats> | null;
     kpm: number;
     kick_attempt_count: number;
     our_friend: boolean;
     sourcebans: SourcebansRecord[];
     matches: Match[];
+    transient_marks: string[];
+    suspicion: SuspicionScore;
+}
+
+export interface WeaponStats {
+    kills: number;
+    crits: number;
 }
 
 export interface SourcebansRecord {
@@ -151,6 +196,8 @@ export interface List {
     name: string;
     enabled: boolean;
     url: string;
+    weight: number;
+    severity: severity | '';
 }
 
 export interface Link {
@@ -176,6 +223,12 @@ export interface UserSettings {
     chat_warnings_enabled: boolean;
     party_warnings_enabled: boolean;
     kick_tags: string[];
+    kick_score_threshold: number;
+    announce_score_threshold: number;
+    mark_ttl_days: Record<string, number>;
+    attributes: AttributeDefinition[];
+    spam_detection: SpamDetectionConfig;
+    bot_wave_detection: BotWaveConfig;
     voice_bans_enabled: boolean;
     debug_log_enabled: boolean;
     lists: List[];
@@ -189,6 +242,36 @@ export interface UserSettings {
     unique_tags: string[];
 }
 
+export interface AttributeDefinition {
+    name: string;
+    aliases?: string[];
+    display_name?: string;
+    description?: string;
+    color?: string;
+    severity?: severity;
+    kick: boolean;
+}
+
+export interface SpamDetectionConfig {
+    enabled: boolean;
+    repeat_count: number;
+    repeat_window: number;
+    repeat_similarity: number;
+    rate_count: number;
+    rate_window: number;
+    length_factor: number;
+    min_length: number;
+}
+
+export interface BotWaveConfig {
+    enabled: boolean;
+    join_window: number;
+    min_group_size: number;
+    fresh_account_days: number;
+    account_age_spread: number;
+    score_threshold: number;
+}
+
 export interface UserNote {
     note: string;
 }
@@ -214,8 +297,11 @@ export const saveUserNote = async (steamId: string, notes: string) =>
 export const deleteUserNote = async (steamId: string) =>
     await call<UserNote>('DELETE', `/api/notes/${steamId}`);
 
-export const markUser = async (steamId: string, attrs: string[]) =>
-    await call('POST', `/api/mark/${steamId}`, { attrs });
+export const markUser = async (
+    steamId: string,
+    attrs: string[],
+    expires?: Date
+) => await call('POST', `/api/mark/${steamId}`, { attrs, expires });
 
 export const unmarkUser = async (steamId: string) =>
     await call('DELETE', `/api/mark/${steamId}`);
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leighmacdonald/bd/rules"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+)
+
+const (
+	// impersonationOrigin is used as the origin of all matches produced by the impersonation detector.
+	impersonationOrigin = "Impersonation Detector"
+	// impersonationAttribute is the attribute given to players detected as impersonating another player.
+	impersonationAttribute = "impersonator"
+)
+
+// impersonation is a player using the same name as another, more trusted, player.
+type impersonation struct {
+	impersonator PlayerState
+	target       PlayerState
+}
+
+// normaliseName folds the name into the form used to compare names, so that look-alike characters, invisible
+// characters, case and extra whitespace cannot be used to make a copied name appear unique.
+func normaliseName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(rules.NormalizeText(name)), " "))
+}
+
+// moreTrusted returns true if player a should be trusted over player b when they share the same name. We are always
+// trusted the most, followed by whitelisted players, our friends and then the older of the two accounts.
+func moreTrusted(a PlayerState, b PlayerState, ourSID steamid.SteamID) bool {
+	switch {
+	case a.SteamID == ourSID || b.SteamID == ourSID:
+		return a.SteamID == ourSID
+	case a.Whitelist != b.Whitelist:
+		return a.Whitelist
+	case a.OurFriend != b.OurFriend:
+		return a.OurFriend
+	case a.AccountCreatedOn.IsZero() != b.AccountCreatedOn.IsZero():
+		return !a.AccountCreatedOn.IsZero()
+	case !a.AccountCreatedOn.Equal(b.AccountCreatedOn):
+		return a.AccountCreatedOn.Before(b.AccountCreatedOn)
+	default:
+		// Steam ids are allocated sequentially, so the lower id is the older account
+		return a.SteamID.Int64() < b.SteamID.Int64()
+	}
+}
+
+// findImpersonators groups the players by their normalised names. Within each group the most trusted player is
+// considered the original, and every other player in the group is returned as impersonating them.
+func findImpersonators(players []PlayerState, ourSID steamid.SteamID) []impersonation {
+	var (
+		groups = map[string][]PlayerState{}
+		order  []string
+	)
+
+	for _, player := range players {
+		name := normaliseName(player.Personaname)
+		if name == "" {
+			continue
+		}
+
+		if _, found := groups[name]; !found {
+			order = append(order, name)
+		}
+
+		groups[name] = append(groups[name], player)
+	}
+
+	var found []impersonation
+
+	for _, name := range order {
+		group := groups[name]
+		if len(group) < 2 {
+			continue
+		}
+
+		target := group[0]
+		for _, player := range group[1:] {
+			if moreTrusted(player, target, ourSID) {
+				target = player
+			}
+		}
+
+		for _, player := range group {
+			if player.SteamID != target.SteamID {
+				found = append(found, impersonation{impersonator: player, target: target})
+			}
+		}
+	}
+
+	return found
+}
+
+// newImpersonationMatch creates a match for impersonating the target. It only depends on the targets steam id so
+// that the same impersonation is not matched again when either player changes their name.
+func newImpersonationMatch(target steamid.SteamID) rules.MatchResult {
+	return rules.MatchResult{
+		Origin:      impersonationOrigin,
+		Attributes:  []string{impersonationAttribute},
+		MatcherType: "name_impersonation",
+		Pattern:     target.String(),
+		Description: fmt.Sprintf("Using the same name as %s", target.String()),
+		Severity:    rules.SeverityHigh,
+		Weight:      rules.SeverityHigh.Weight(),
+	}
+}
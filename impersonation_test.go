@@ -0,0 +1,107 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/bd/platform"
+	"github.com/leighmacdonald/bd/rules"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindImpersonators(t *testing.T) {
+	var (
+		us       = PlayerState{SteamID: steamid.New(76561197961279983), Personaname: "Me"}
+		original = PlayerState{
+			SteamID:          steamid.New(76561197970669109),
+			Personaname:      "Player",
+			AccountCreatedOn: time.Now().AddDate(-10, 0, 0),
+		}
+		copied = PlayerState{
+			SteamID:          steamid.New(76561197992870439),
+			Personaname:      "plаyer​", // Cyrillic a and a zero width space
+			AccountCreatedOn: time.Now().AddDate(0, 0, -1),
+		}
+		ourCopy = PlayerState{SteamID: steamid.New(76561198084134025), Personaname: " me ", Whitelist: true}
+		other   = PlayerState{SteamID: steamid.New(76561198004429398), Personaname: "Other"}
+	)
+
+	found := findImpersonators([]PlayerState{copied, original, us, ourCopy, other}, us.SteamID)
+	require.Len(t, found, 2)
+	require.Equal(t, copied.SteamID, found[0].impersonator.SteamID)
+	require.Equal(t, original.SteamID, found[0].target.SteamID)
+	require.Equal(t, ourCopy.SteamID, found[1].impersonator.SteamID)
+	require.Equal(t, us.SteamID, found[1].target.SteamID)
+
+	// Without account ages, the newer steam id is considered the impersonator
+	original.AccountCreatedOn = time.Time{}
+	copied.AccountCreatedOn = time.Time{}
+
+	found = findImpersonators([]PlayerState{copied, original}, us.SteamID)
+	require.Len(t, found, 1)
+	require.Equal(t, copied.SteamID, found[0].impersonator.SteamID)
+
+	match := newImpersonationMatch(original.SteamID)
+	require.Equal(t, impersonationOrigin, match.Origin)
+	require.Equal(t, original.SteamID.String(), match.Pattern)
+}
+
+func TestDetectImpersonation(t *testing.T) {
+	var (
+		state    = newGameState(nil, newSettingsManager(platform.New()), newPlayerStates(), nil, nil, rules.New(), nil)
+		original = PlayerState{SteamID: steamid.New(76561197970669109), Personaname: "Player", IsConnected: true}
+		copied   = PlayerState{SteamID: steamid.New(76561197992870439), Personaname: "player", IsConnected: true}
+	)
+
+	// Updates are made to the stored players so that their existing matches are kept
+	modify := func(sid steamid.SteamID, update func(player *PlayerState)) {
+		player, errPlayer := state.players.bySteamID(sid)
+		require.NoError(t, errPlayer)
+
+		update(&player)
+		state.players.update(player)
+	}
+
+	impersonations := func(sid steamid.SteamID) []rules.MatchResult {
+		player, errPlayer := state.players.bySteamID(sid)
+		require.NoError(t, errPlayer)
+
+		var matches []rules.MatchResult
+
+		for _, match := range player.Matches {
+			if match.Origin == impersonationOrigin {
+				matches = append(matches, match)
+			}
+		}
+
+		return matches
+	}
+
+	state.players.update(original)
+	state.players.update(copied)
+	state.detectImpersonation()
+	require.Len(t, impersonations(copied.SteamID), 1)
+	require.Empty(t, impersonations(original.SteamID))
+
+	// Renaming the original player does not create another match for the same target
+	modify(original.SteamID, func(player *PlayerState) { player.Personaname = "PLAYER " })
+	state.detectImpersonation()
+	require.Len(t, impersonations(copied.SteamID), 1)
+
+	// Once the account ages are known, the newer steam id may turn out to be the original
+	modify(original.SteamID, func(player *PlayerState) { player.AccountCreatedOn = time.Now().AddDate(0, 0, -1) })
+	modify(copied.SteamID, func(player *PlayerState) { player.AccountCreatedOn = time.Now().AddDate(-10, 0, 0) })
+	state.detectImpersonation()
+	require.Empty(t, impersonations(copied.SteamID))
+	require.Len(t, impersonations(original.SteamID), 1)
+
+	player, errPlayer := state.players.bySteamID(copied.SteamID)
+	require.NoError(t, errPlayer)
+	require.Zero(t, player.Suspicion.Total)
+
+	// The match is removed when the impersonator changes their name
+	modify(original.SteamID, func(player *PlayerState) { player.Personaname = "someone else" })
+	state.detectImpersonation()
+	require.Empty(t, impersonations(original.SteamID))
+}
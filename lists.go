@@ -1,8 +1,8 @@
 package main
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -27,13 +27,18 @@ type listManager struct {
 	re          *rules.Engine
 	settingsMgr *settingsManager
 	cache       Cache
+	local       *localLists
+	// listsChanged is signalled when the configured lists have been updated.
+	listsChanged chan struct{}
 }
 
-func newListManager(cache Cache, re *rules.Engine, settingsMgr *settingsManager) listManager {
+func newListManager(cache Cache, re *rules.Engine, settingsMgr *settingsManager, local *localLists) listManager {
 	return listManager{
-		cache:       cache,
-		re:          re,
-		settingsMgr: settingsMgr,
+		cache:        cache,
+		re:           re,
+		settingsMgr:  settingsMgr,
+		local:        local,
+		listsChanged: make(chan struct{}, 1),
 	}
 }
 
@@ -76,19 +81,36 @@ func (lm listManager) downloadLists(ctx context.Context, lists ListConfigCollect
 
 		body, errFetch := fetchURL(ctx, client, listConfig.URL)
 		if errFetch != nil {
-			return fmt.Errorf("%w: %s", errFetchPlayerList, listConfig.URL)
+			cached, errCached := lm.cachedList(listConfig.URL)
+			if errCached != nil {
+				return fmt.Errorf("%w: %s", errFetchPlayerList, listConfig.URL)
+			}
+
+			slog.Warn("Failed to download list, using cached copy", slog.String("url", listConfig.URL), errAttr(errFetch))
+
+			body = cached
+		}
+
+		raw := body
+
+		format := rules.DetectFormat(listConfig.URL, body)
+		if format == rules.FormatJSON {
+			body = fixSteamIDFormat(body)
 		}
 
-		body = fixSteamIDFormat(body)
 		dur := time.Since(start)
 
 		switch listConfig.ListType {
 		case ListTypeTF2BDPlayerList:
 			var result rules.PlayerListSchema
-			if errParse := json.Unmarshal(body, &result); errParse != nil {
+			if errParse := rules.DecodeList(body, format, &result); errParse != nil {
 				return errors.Join(errParse, errDecodeResponse)
 			}
 
+			result.SourceURL = listConfig.URL
+			result.Weight = listConfig.Weight
+			result.Severity = listConfig.Severity
+
 			mutex.Lock()
 			playerLists = append(playerLists, result)
 			mutex.Unlock()
@@ -96,10 +118,14 @@ func (lm listManager) downloadLists(ctx context.Context, lists ListConfigCollect
 			slog.Info("Downloaded activePlayers successfully", slog.Duration("duration", dur), slog.String("name", result.FileInfo.Title))
 		case ListTypeTF2BDRules:
 			var result rules.RuleSchema
-			if errParse := json.Unmarshal(body, &result); errParse != nil {
+			if errParse := rules.DecodeList(body, format, &result); errParse != nil {
 				return errors.Join(errParse, errDecodeResponse)
 			}
 
+			result.SourceURL = listConfig.URL
+			result.Weight = listConfig.Weight
+			result.Severity = listConfig.Severity
+
 			mutex.Lock()
 			rulesLists = append(rulesLists, result)
 			mutex.Unlock()
@@ -107,6 +133,13 @@ func (lm listManager) downloadLists(ctx context.Context, lists ListConfigCollect
 			slog.Info("Downloaded rules successfully", slog.Duration("duration", dur), slog.String("name", result.FileInfo.Title))
 		}
 
+		// Only lists which decoded successfully are cached, so that an error page never replaces a good copy
+		if errFetch == nil {
+			if errSet := lm.cache.Set(TypeLists, listCacheKey(listConfig.URL), bytes.NewReader(raw)); errSet != nil {
+				slog.Error("Failed to cache list", slog.String("url", listConfig.URL), errAttr(errSet))
+			}
+		}
+
 		return nil
 	}
 
@@ -133,10 +166,141 @@ func (lm listManager) downloadLists(ctx context.Context, lists ListConfigCollect
 	return playerLists, rulesLists
 }
 
-// refreshLists updates the 3rd party player lists using their update url.
+// cachedList returns the copy of the list saved when it was last downloaded.
+func (lm listManager) cachedList(listURL string) ([]byte, error) {
+	var cached bytes.Buffer
+	if errCache := lm.cache.Get(TypeLists, listCacheKey(listURL), &cached); errCache != nil {
+		return nil, errCache
+	}
+
+	if cached.Len() == 0 {
+		return nil, errCacheExpired
+	}
+
+	return cached.Bytes(), nil
+}
+
+// listCacheKey returns the key used to cache the list, urls cannot be used directly as they are not valid file names.
+func listCacheKey(listURL string) string {
+	return rules.HashBytes([]byte(listURL))
+}
+
+// start downloads and imports the enabled lists. The lists are then kept in sync with the settings as they are
+// updated, so that enabling or disabling a list takes effect without a restart. Lists which fail to download are
+// retried periodically until they succeed.
 func (lm listManager) start(ctx context.Context) {
-	settings := lm.settingsMgr.Settings()
-	playerLists, ruleLists := lm.downloadLists(ctx, settings.Lists)
+	loaded := lm.sync(ctx, map[string]bool{}, lm.settingsMgr.Settings().Lists)
+	pruneTicker := time.NewTicker(DurationPruneExpiredMarks)
+	retryTicker := time.NewTicker(DurationListRetry)
+
+	defer pruneTicker.Stop()
+	defer retryTicker.Stop()
+
+	lm.pruneExpiredMarks()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-lm.listsChanged:
+			loaded = lm.sync(ctx, loaded, lm.settingsMgr.Settings().Lists)
+		case <-retryTicker.C:
+			if lists := lm.settingsMgr.Settings().Lists; hasPendingLists(loaded, lists) {
+				loaded = lm.sync(ctx, loaded, lists)
+			}
+		case <-pruneTicker.C:
+			lm.pruneExpiredMarks()
+		}
+	}
+}
+
+// pruneExpiredMarks removes any expired marks from the local player list, saving it if anything was removed.
+func (lm listManager) pruneExpiredMarks() {
+	pruned := lm.re.PruneExpired(time.Now())
+	if pruned == 0 {
+		return
+	}
+
+	lm.local.savePlayers()
+
+	slog.Info("Pruned expired marks", slog.Int("count", pruned))
+}
+
+// hasPendingLists returns true if any of the enabled lists are not loaded, such as when their download failed.
+func hasPendingLists(loaded map[string]bool, lists ListConfigCollection) bool {
+	for _, listConfig := range lists {
+		if listConfig.Enabled && !loaded[listConfig.URL] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// notifyListsChanged signals that the configured lists have changed and should be reloaded.
+func (lm listManager) notifyListsChanged() {
+	select {
+	case lm.listsChanged <- struct{}{}:
+	default:
+		// A reload is already pending and will use the current settings.
+	}
+}
+
+// sync unloads any of the loaded lists that are no longer enabled and imports any newly enabled lists. The weights
+// and severities of lists which remain loaded are updated. The updated set of loaded list urls is returned.
+func (lm listManager) sync(ctx context.Context, loaded map[string]bool, lists ListConfigCollection) map[string]bool {
+	var (
+		enabled = map[string]bool{}
+		current = map[string]bool{}
+		pending ListConfigCollection
+	)
+
+	for _, listConfig := range lists {
+		if !listConfig.Enabled {
+			continue
+		}
+
+		enabled[listConfig.URL] = true
+
+		if loaded[listConfig.URL] {
+			current[listConfig.URL] = true
+
+			if errScoring := lm.re.SetListScoring(listConfig.URL, listConfig.Weight, listConfig.Severity); errScoring != nil {
+				slog.Error("Failed to update list scoring", slog.String("url", listConfig.URL), errAttr(errScoring))
+			}
+		} else {
+			pending = append(pending, listConfig)
+		}
+	}
+
+	for listURL := range loaded {
+		if enabled[listURL] {
+			continue
+		}
+
+		if errRemove := lm.re.RemoveList(listURL); errRemove != nil {
+			slog.Error("Failed to unload list", slog.String("url", listURL), errAttr(errRemove))
+		} else {
+			slog.Info("Unloaded list", slog.String("url", listURL))
+		}
+	}
+
+	for listURL := range lm.importLists(ctx, pending) {
+		current[listURL] = true
+	}
+
+	return current
+}
+
+// importLists downloads and imports the lists, returning the urls of the lists that were successfully imported.
+func (lm listManager) importLists(ctx context.Context, lists ListConfigCollection) map[string]bool {
+	imported := map[string]bool{}
+
+	if len(lists) == 0 {
+		return imported
+	}
+
+	playerLists, ruleLists := lm.downloadLists(ctx, lists)
 	for _, list := range playerLists {
 		boundList := list
 
@@ -145,6 +309,8 @@ func (lm listManager) start(ctx context.Context) {
 			slog.Error("Failed to import player list", slog.String("name", boundList.FileInfo.Title), errAttr(errImport))
 		} else {
 			slog.Info("Imported player list", slog.String("name", boundList.FileInfo.Title), slog.Int("count", count))
+
+			imported[boundList.SourceURL] = true
 		}
 	}
 
@@ -153,9 +319,14 @@ func (lm listManager) start(ctx context.Context) {
 
 		count, errImport := lm.re.ImportRules(&boundList)
 		if errImport != nil {
-			slog.Error("Failed to import rules list (%s): %v\n", slog.String("name", boundList.FileInfo.Title), errAttr(errImport))
-		} else {
-			slog.Info("Imported rules list", slog.String("name", boundList.FileInfo.Title), slog.Int("count", count))
+			// Invalid rules are skipped, the rest of the list is still usable.
+			slog.Error("Failed to import some rules", slog.String("name", boundList.FileInfo.Title), errAttr(errImport))
 		}
+
+		slog.Info("Imported rules list", slog.String("name", boundList.FileInfo.Title), slog.Int("count", count))
+
+		imported[boundList.SourceURL] = true
 	}
+
+	return imported
 }
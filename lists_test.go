@@ -1,8 +1,13 @@
 package main
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/leighmacdonald/bd/rules"
 	"github.com/stretchr/testify/require"
 )
 
@@ -97,3 +102,44 @@ func TestListParse(t *testing.T) {
 	good := fixSteamIDFormat(badSchema)
 	require.Equal(t, goodSchema, good)
 }
+
+func TestHasPendingLists(t *testing.T) {
+	lists := ListConfigCollection{
+		{URL: "https://example.com/a.json", Enabled: true},
+		{URL: "https://example.com/b.json", Enabled: false},
+	}
+
+	require.True(t, hasPendingLists(map[string]bool{}, lists))
+	require.False(t, hasPendingLists(map[string]bool{"https://example.com/a.json": true}, lists))
+}
+
+func TestDownloadListsCache(t *testing.T) {
+	list := `{"file_info": {"title": "remote"}, "players": [{"steamid": "76561197961279983", "attributes": ["cheater"]}]}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(list))
+	}))
+
+	cache, errCache := NewCache(t.TempDir(), time.Hour)
+	require.NoError(t, errCache)
+
+	var (
+		manager = newListManager(cache, rules.New(), nil, nil)
+		lists   = ListConfigCollection{{ListType: ListTypeTF2BDPlayerList, Enabled: true, URL: server.URL + "/list.json"}}
+	)
+
+	players, _ := manager.downloadLists(context.Background(), lists)
+	require.Len(t, players, 1)
+
+	// Once offline, the copy from the last download is used
+	server.Close()
+
+	cached, _ := manager.downloadLists(context.Background(), lists)
+	require.Len(t, cached, 1)
+	require.Equal(t, players[0].FileInfo, cached[0].FileInfo)
+	require.Len(t, cached[0].Players, 1)
+
+	missing, _ := manager.downloadLists(context.Background(),
+		ListConfigCollection{{ListType: ListTypeTF2BDPlayerList, Enabled: true, URL: server.URL + "/other.json"}})
+	require.Empty(t, missing)
+}
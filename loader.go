@@ -26,6 +26,10 @@ type playerDataUpdate struct {
 	bans       steamweb.PlayerBanState
 	sourcebans []models.SbBanRecord
 	friends    []steamweb.Friend
+	// avatarMatches contains any matches from the avatar rules against the players current avatar
+	avatarMatches []rules.MatchResult
+	// avatarPerceptualHash is the perceptual hash of the players current avatar
+	avatarPerceptualHash string
 }
 
 type playerDataLoader struct {
@@ -35,16 +39,18 @@ type playerDataLoader struct {
 	db                 store.Querier
 	settings           *settingsManager
 	re                 *rules.Engine
+	avatars            avatarLoader
 }
 
 func newPlayerDataLoader(db store.Querier, ds DataSource, settings *settingsManager, re *rules.Engine,
-	profileUpdateQueue chan steamid.SteamID, playerDataChan chan playerDataUpdate,
+	avatars avatarLoader, profileUpdateQueue chan steamid.SteamID, playerDataChan chan playerDataUpdate,
 ) *playerDataLoader {
 	return &playerDataLoader{
 		db:                 db,
 		datasource:         ds,
 		settings:           settings,
 		re:                 re,
+		avatars:            avatars,
 		profileUpdateQueue: profileUpdateQueue,
 		playerDataChan:     playerDataChan,
 	}
@@ -102,6 +108,8 @@ func (p *playerDataLoader) start(ctx context.Context) {
 					u.sourcebans = sourcebans
 				}
 
+				u.avatarMatches, u.avatarPerceptualHash = p.matchAvatar(ctx, steamID, u.summary.AvatarHash)
+
 				updates = append(updates, u)
 			}
 
@@ -120,6 +128,23 @@ func (p *playerDataLoader) start(ctx context.Context) {
 	}
 }
 
+// matchAvatar downloads the players avatar and checks it against the avatar rules, returning the matches and the
+// perceptual hash of the avatar.
+func (p *playerDataLoader) matchAvatar(ctx context.Context, steamID steamid.SteamID, hash string) ([]rules.MatchResult, string) {
+	if hash == "" {
+		return nil, ""
+	}
+
+	matches, perceptualHash, errMatch := p.avatars.match(ctx, hash)
+	if errMatch != nil {
+		slog.Error("Failed to match player avatar", errAttr(errMatch), sidAttr(steamID))
+
+		return nil, ""
+	}
+
+	return matches, perceptualHash
+}
+
 func (p *playerDataLoader) saveSourceBans(ctx context.Context, steamID steamid.SteamID, records []models.SbBanRecord) {
 	if err := p.db.SourcebansDelete(ctx, steamID.Int64()); err != nil {
 		slog.Error("failed to delete sourcebans records", errAttr(err))
@@ -1,12 +1,14 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net"
+	"net/netip"
 	"runtime"
 	"strconv"
 	"strings"
@@ -60,11 +62,9 @@ func (e *eventBroadcaster) registerConsumer(consumer chan LogEvent, eventTypes .
 }
 
 type logIngest struct {
-	tail   *tail.Tail
-	logger *slog.Logger
-	parser Parser
-	// Use mostly for testing, allowing simple feeding of an existing console.log file
-	external    chan string
+	tail        *tail.Tail
+	logger      *slog.Logger
+	parser      Parser
 	broadcaster *eventBroadcaster
 }
 
@@ -92,7 +92,6 @@ func newLogIngest(path string, parser Parser, echo bool, broadcaster *eventBroad
 		logger:      slog.Default().WithGroup("logReader"),
 		parser:      parser,
 		broadcaster: broadcaster,
-		external:    make(chan string),
 	}, nil
 }
 
@@ -110,12 +109,6 @@ func (li *logIngest) lineEmitter(ctx context.Context, incoming chan string) {
 				continue
 			}
 
-			incoming <- line
-		case externalLine := <-li.external:
-			line := strings.TrimSuffix(externalLine, "\r")
-			if line == "" {
-				continue
-			}
 			incoming <- line
 		case <-ctx.Done():
 			return
@@ -153,40 +146,90 @@ func (li *logIngest) start(ctx context.Context) {
 type srcdsPacket byte
 
 const (
-	// Normal log messages (unsupported).
+	// Normal log messages, these are only accepted from the allowed sources.
 	s2aLogString srcdsPacket = 0x52
 	// Sent when using sv_logsecret.
 	s2aLogString2 srcdsPacket = 0x53
+
+	// maxLogPacketSize is the largest possible udp payload, so that long lines are never truncated.
+	maxLogPacketSize = 65535
 )
 
 type udpListener struct {
 	udpAddr     *net.UDPAddr
+	secret      int32
+	allowPlain  bool
+	sources     []netip.Prefix
 	broadcaster *eventBroadcaster
-	parser      *logParser
+	parser      Parser
 }
 
-func newUDPListener(logAddr string, parser *logParser, broadcaster *eventBroadcaster) (*udpListener, error) {
+// newUDPListener creates a listener for log packets sent via logaddress_add. Packets using sv_logsecret must match
+// the secret. Plain log packets are only accepted when allowPlain is set, and only from the plainSources, which
+// may be ip addresses or cidr ranges.
+func newUDPListener(logAddr string, secret int32, allowPlain bool, plainSources []string, parser Parser,
+	broadcaster *eventBroadcaster,
+) (*udpListener, error) {
 	udpAddr, errResolveUDP := net.ResolveUDPAddr("udp4", logAddr)
 	if errResolveUDP != nil {
 		return nil, errors.Join(errResolveUDP, errResolveAddr)
 	}
 
+	sources, errSources := parseLogSources(plainSources)
+	if errSources != nil {
+		return nil, errSources
+	}
+
 	return &udpListener{
 		udpAddr:     udpAddr,
+		secret:      secret,
+		allowPlain:  allowPlain,
+		sources:     sources,
 		broadcaster: broadcaster,
 		parser:      parser,
 	}, nil
 }
 
-// Start initiates the udp network log read loop. DNS names are used to
-// map the server logs to the internal known server id. The DNS is updated
-// every 60 minutes so that it remains up to date.
-func (l *udpListener) start(ctx context.Context) {
-	type newMsg struct {
-		source int64
-		body   string
+// parseLogSources parses the list of ip addresses and cidr ranges that plain log packets are accepted from.
+func parseLogSources(sources []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(sources))
+
+	for _, source := range sources {
+		source = strings.TrimSpace(source)
+
+		if prefix, errPrefix := netip.ParsePrefix(source); errPrefix == nil {
+			prefixes = append(prefixes, prefix.Masked())
+
+			continue
+		}
+
+		addr, errAddr := netip.ParseAddr(source)
+		if errAddr != nil {
+			return nil, errors.Join(errAddr, fmt.Errorf("%w: %s", errLogSource, source))
+		}
+
+		prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+
+	return prefixes, nil
+}
+
+// sourceAllowed returns true if plain log packets are accepted from the address.
+func (l *udpListener) sourceAllowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	for _, prefix := range l.sources {
+		if prefix.Contains(addr) {
+			return true
+		}
 	}
 
+	return false
+}
+
+// start opens the udp socket configured with logaddress_add and begins reading log packets from it until the
+// context is cancelled.
+func (l *udpListener) start(ctx context.Context) {
 	connection, errListenUDP := net.ListenUDP("udp4", l.udpAddr)
 	if errListenUDP != nil {
 		slog.Error("Failed to start log listener", errAttr(errListenUDP))
@@ -194,38 +237,32 @@ type newMsg struct {
 		return
 	}
 
-	defer func() {
-		if errConnClose := connection.Close(); errConnClose != nil {
-			slog.Error("Failed to close connection cleanly", errAttr(errConnClose))
-		}
-	}()
-
 	slog.Info("Starting log reader",
-		slog.String("listen_addr", fmt.Sprintf("%s/udp", l.udpAddr.String())))
+		slog.String("listen_addr", fmt.Sprintf("%s/udp", connection.LocalAddr().String())))
 
-	var (
-		count          = uint64(0)
-		insecureCount  = uint64(0)
-		errCount       = uint64(0)
-		msgIngressChan = make(chan newMsg)
-	)
+	l.serve(ctx, connection)
+}
 
+// serve reads log packets from the connection, parsing and broadcasting the log line contained in each of them.
+// The connection is closed once the context is cancelled.
+func (l *udpListener) serve(ctx context.Context, connection *net.UDPConn) {
 	go func() {
 		// Close the listener on context cancellation
 		<-ctx.Done()
-		if errClose := connection.Close(); errClose != nil {
+		if errClose := connection.Close(); errClose != nil && !errors.Is(errClose, net.ErrClosed) {
 			slog.Error("failed to close udp connection cleanly", errAttr(errClose))
 		}
 	}()
 
-	startTime := time.Now()
-	buffer := make([]byte, 1024)
+	var (
+		count     = uint64(0)
+		errCount  = uint64(0)
+		startTime = time.Now()
+		buffer    = make([]byte, maxLogPacketSize)
+	)
 
 	for {
-		// Reuse memory
-		clear(buffer)
-
-		readLen, _, errReadUDP := connection.ReadFromUDP(buffer)
+		readLen, source, errReadUDP := connection.ReadFromUDPAddrPort(buffer)
 		if errReadUDP != nil {
 			if errors.Is(errReadUDP, net.ErrClosed) {
 				return
@@ -236,51 +273,81 @@ type newMsg struct {
 			continue
 		}
 
-		switch srcdsPacket(buffer[4]) {
-		case s2aLogString:
-			if insecureCount%10000 == 0 {
-				slog.Error("Using unsupported log packet type 0x52",
-					slog.Int64("count", int64(insecureCount+1)))
+		line, errPacket := l.decodePacket(buffer[:readLen], source.Addr())
+		if errPacket != nil {
+			if errCount%10000 == 0 {
+				slog.Warn("Received invalid log packet", errAttr(errPacket), slog.Uint64("errors", errCount+1))
 			}
 
-			insecureCount++
 			errCount++
-		case s2aLogString2:
-			line := string(buffer)
 
-			idx := strings.Index(line, "L ")
-			if idx == -1 {
-				slog.Warn("Received malformed log message: Failed to find marker")
+			continue
+		}
 
-				errCount++
+		var logEvent LogEvent
+		if errParse := l.parser.parse(line, &logEvent); errParse == nil {
+			l.broadcaster.broadcast(logEvent)
+		}
 
-				continue
-			}
+		count++
 
-			secret, errConv := strconv.ParseInt(line[5:idx], 10, 32)
-			if errConv != nil {
-				slog.Error("Received malformed log message: Failed to parse secret",
-					errAttr(errConv))
+		if count%10000 == 0 {
+			rate := float64(count) / time.Since(startTime).Seconds()
 
-				errCount++
+			slog.Debug("UDP SRCDS Logger Packets",
+				slog.Uint64("count", count),
+				slog.Float64("messages/sec", rate),
+				slog.Uint64("errors", errCount))
 
-				continue
-			}
+			startTime = time.Now()
+		}
+	}
+}
 
-			msgIngressChan <- newMsg{source: secret, body: line[idx : readLen-2]}
+// decodePacket validates the packet and returns the log line it contains, without the "L " marker. Log packets
+// have the following format, the secret is only included in S2A_LOGSTRING2 packets:
+//
+//	\xff\xff\xff\xff <type> [secret] L MM/DD/YYYY - HH:MM:SS: <message>\n\x00
+func (l *udpListener) decodePacket(packet []byte, source netip.Addr) (string, error) {
+	const headerLen = 5
 
-			count++
+	if len(packet) < headerLen || !bytes.Equal(packet[:4], []byte{0xff, 0xff, 0xff, 0xff}) {
+		return "", errLogPacketHeader
+	}
 
-			if count%10000 == 0 {
-				rate := float64(count) / time.Since(startTime).Seconds()
+	body := string(packet[headerLen:])
 
-				slog.Debug("UDP SRCDS Logger Packets",
-					slog.Uint64("count", count),
-					slog.Float64("messages/sec", rate),
-					slog.Uint64("errors", errCount))
+	idx := strings.Index(body, "L ")
+	if idx == -1 {
+		return "", errLogPacketMarker
+	}
 
-				startTime = time.Now()
-			}
+	switch srcdsPacket(packet[4]) {
+	case s2aLogString2:
+		secret, errConv := strconv.ParseInt(body[:idx], 10, 32)
+		if errConv != nil {
+			return "", errors.Join(errConv, errLogPacketSecret)
+		}
+
+		if int32(secret) != l.secret {
+			return "", errLogPacketSecret
 		}
+	case s2aLogString:
+		// Plain log packets are not authenticated, so can be sent by anyone that knows the address
+		if !l.allowPlain {
+			return "", fmt.Errorf("%w: plain log packets are not enabled", errLogPacketType)
+		}
+
+		if idx != 0 {
+			return "", errLogPacketMarker
+		}
+
+		if !l.sourceAllowed(source) {
+			return "", fmt.Errorf("%w: %s", errLogPacketSource, source.String())
+		}
+	default:
+		return "", fmt.Errorf("%w: 0x%x", errLogPacketType, packet[4])
 	}
+
+	return strings.TrimRight(body[idx+2:], "\x00\r\n"), nil
 }
@@ -1,6 +1,10 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
 	"testing"
 	"time"
 
@@ -36,12 +40,53 @@ type tc struct {
 		{
 			text:     "02/24/2023 - 23:37:19: ❤ Ashley ❤ killed [TrC] Nosy with spy_cicle.",
 			match:    true,
-			expected: LogEvent{Type: EvtKill, Player: "❤ Ashley ❤", Victim: "[TrC] Nosy", Timestamp: timeStamp},
+			expected: LogEvent{Type: EvtKill, Player: "❤ Ashley ❤", Victim: "[TrC] Nosy", Weapon: "spy_cicle", Timestamp: timeStamp},
 		},
 		{
-			text:     "02/24/2023 - 23:37:19: ❤ Ashley ❤ killed [TrC] Nosy with spy_cicle. (crit)",
+			text:  "02/24/2023 - 23:37:19: ❤ Ashley ❤ killed [TrC] Nosy with spy_cicle. (crit)",
+			match: true,
+			expected: LogEvent{
+				Type: EvtKill, Player: "❤ Ashley ❤", Victim: "[TrC] Nosy", Weapon: "spy_cicle", Crit: true,
+				Timestamp: timeStamp,
+			},
+		},
+		{
+			text:  "02/24/2023 - 23:37:19: Hassium + Vixian killed [TrC] Nosy with tf_projectile_rocket.",
+			match: true,
+			expected: LogEvent{
+				Type: EvtKill, Player: "Hassium", Assister: "Vixian", Victim: "[TrC] Nosy", Weapon: "tf_projectile_rocket",
+				Timestamp: timeStamp,
+			},
+		},
+		{
+			text:     "02/24/2023 - 23:37:19: [TrC] Nosy suicided.",
 			match:    true,
-			expected: LogEvent{Type: EvtKill, Player: "❤ Ashley ❤", Victim: "[TrC] Nosy", Timestamp: timeStamp},
+			expected: LogEvent{Type: EvtSuicide, Player: "[TrC] Nosy", Victim: "[TrC] Nosy", Timestamp: timeStamp},
+		},
+		{
+			text:     "02/24/2023 - 23:37:19: [TrC] Nosy died.",
+			match:    true,
+			expected: LogEvent{Type: EvtSuicide, Player: "[TrC] Nosy", Victim: "[TrC] Nosy", Weapon: weaponWorld, Timestamp: timeStamp},
+		},
+		{
+			text:     "02/24/2023 - 23:37:19: Player [TrC] Nosy joined team BLU",
+			match:    true,
+			expected: LogEvent{Type: EvtTeam, Player: "[TrC] Nosy", Team: Blu, Timestamp: timeStamp},
+		},
+		{
+			text:     "02/24/2023 - 23:37:19: Player Hassium joined team Spectators",
+			match:    true,
+			expected: LogEvent{Type: EvtTeam, Player: "Hassium", Team: Spec, Timestamp: timeStamp},
+		},
+		{
+			text:     "02/24/2023 - 23:37:19: *You will respawn as Heavy",
+			match:    true,
+			expected: LogEvent{Type: EvtClass, Class: Heavy, Timestamp: timeStamp},
+		},
+		{
+			text:     "02/24/2023 - 23:37:19: *You spawned as Engineer",
+			match:    true,
+			expected: LogEvent{Type: EvtClass, Class: Engineer, Timestamp: timeStamp},
 		},
 		{
 			text:     "02/24/2023 - 23:37:19: Hassium connected",
@@ -110,3 +155,137 @@ type tc struct {
 		}
 	}
 }
+
+func logPacket(packetType srcdsPacket, secret string, line string) []byte {
+	return append([]byte{0xff, 0xff, 0xff, 0xff, byte(packetType)}, []byte(fmt.Sprintf("%sL %s\n\x00", secret, line))...)
+}
+
+// serveTestUDPListener starts the listener on a random local port, returning a client connected to it along with a
+// channel that is closed once the listener stops.
+func serveTestUDPListener(ctx context.Context, t *testing.T, listener *udpListener) (*net.UDPConn, chan struct{}) {
+	t.Helper()
+
+	connection, errListen := net.ListenUDP("udp4", listener.udpAddr)
+	require.NoError(t, errListen)
+
+	done := make(chan struct{})
+
+	go func() {
+		listener.serve(ctx, connection)
+		close(done)
+	}()
+
+	client, errDial := net.DialUDP("udp4", nil, connection.LocalAddr().(*net.UDPAddr))
+	require.NoError(t, errDial)
+
+	t.Cleanup(func() {
+		LogClose(client)
+	})
+
+	return client, done
+}
+
+func sendPackets(t *testing.T, client *net.UDPConn, packets ...[]byte) {
+	t.Helper()
+
+	for _, packet := range packets {
+		_, errWrite := client.Write(packet)
+		require.NoError(t, errWrite)
+	}
+}
+
+func waitEvent(t *testing.T, events chan LogEvent) LogEvent {
+	t.Helper()
+
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second * 5):
+		t.Fatal("Timed out waiting for log event")
+	}
+
+	return LogEvent{}
+}
+
+func TestUDPListener(t *testing.T) {
+	const secret = 12345
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		broadcaster = newEventBroadcaster()
+		events      = make(chan LogEvent, 10)
+	)
+
+	broadcaster.registerConsumer(events, EvtMsg)
+
+	listener, errListener := newUDPListener("127.0.0.1:0", secret, false, nil, newLogParser(), broadcaster)
+	require.NoError(t, errListener)
+
+	client, done := serveTestUDPListener(ctx, t, listener)
+
+	sendPackets(t, client,
+		logPacket(s2aLogString2, "999", "02/24/2023 - 23:37:19: Intruder :  wrong secret"),
+		logPacket(s2aLogString, "", "02/24/2023 - 23:37:19: Intruder :  no secret"),
+		[]byte("not a log packet"),
+		logPacket(s2aLogString2, fmt.Sprintf("%d", secret), "02/24/2023 - 23:37:19: Hassium :  hello there"),
+	)
+
+	require.Equal(t, LogEvent{
+		Type:      EvtMsg,
+		Player:    "Hassium",
+		Message:   "hello there",
+		Timestamp: time.Date(2023, time.February, 24, 23, 37, 19, 0, time.UTC),
+	}, waitEvent(t, events))
+	require.Empty(t, events)
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Fatal("Listener did not stop")
+	}
+}
+
+func TestUDPListenerPlain(t *testing.T) {
+	const secret = 12345
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		broadcaster = newEventBroadcaster()
+		events      = make(chan LogEvent, 10)
+		longMessage = strings.TrimSpace(strings.Repeat("long message ", 200))
+	)
+
+	broadcaster.registerConsumer(events, EvtMsg)
+
+	_, errSources := newUDPListener("127.0.0.1:0", secret, true, []string{"not an ip"}, newLogParser(), broadcaster)
+	require.ErrorIs(t, errSources, errLogSource)
+
+	allowed, errAllowed := newUDPListener("127.0.0.1:0", secret, true, []string{"10.0.0.1", "127.0.0.0/8"},
+		newLogParser(), broadcaster)
+	require.NoError(t, errAllowed)
+
+	client, _ := serveTestUDPListener(ctx, t, allowed)
+
+	// Packets longer than the previous 1024 byte buffer must not be truncated
+	sendPackets(t, client, logPacket(s2aLogString, "", "02/24/2023 - 23:37:19: Hassium :  "+longMessage))
+	require.Equal(t, longMessage, waitEvent(t, events).Message)
+
+	denied, errDenied := newUDPListener("127.0.0.1:0", secret, true, []string{"10.0.0.0/8"}, newLogParser(), broadcaster)
+	require.NoError(t, errDenied)
+
+	deniedClient, _ := serveTestUDPListener(ctx, t, denied)
+
+	sendPackets(t, deniedClient,
+		logPacket(s2aLogString, "", "02/24/2023 - 23:37:19: Intruder :  not allowed"),
+		logPacket(s2aLogString2, fmt.Sprintf("%d", secret), "02/24/2023 - 23:37:19: Hassium :  secret still works"),
+	)
+
+	require.Equal(t, "secret still works", waitEvent(t, events).Message)
+	require.Empty(t, events)
+}
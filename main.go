@@ -2,7 +2,6 @@
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"log/slog"
 	"net/http"
@@ -29,49 +28,41 @@
 func createRulesEngine(sm *settingsManager) *rules.Engine {
 	rulesEngine := rules.New()
 
-	if sm.Settings().RunMode != ModeTest { //nolint:nestif
+	if errTaxonomy := rulesEngine.SetTaxonomy(sm.Settings().taxonomyAttributes()); errTaxonomy != nil {
+		slog.Error("Invalid attribute taxonomy, using defaults", errAttr(errTaxonomy))
+	}
+
+	if sm.Settings().RunMode != ModeTest {
 		// Try and load our existing custom players
-		if platform.Exists(sm.LocalPlayerListPath()) {
-			input, errInput := os.Open(sm.LocalPlayerListPath())
-			if errInput != nil {
-				slog.Error("Failed to open local player list", errAttr(errInput))
+		var localPlayersList rules.PlayerListSchema
+
+		if _, errRead := readRecoverable(sm.LocalPlayerListPath(), localListBackupCount, &localPlayersList); errRead != nil {
+			if !errors.Is(errRead, os.ErrNotExist) {
+				slog.Error("Failed to read local player list", errAttr(errRead))
+			}
+		} else {
+			count, errPlayerImport := rulesEngine.ImportPlayers(&localPlayersList)
+			if errPlayerImport != nil {
+				slog.Error("Failed to import local player list", errAttr(errPlayerImport))
 			} else {
-				var localPlayersList rules.PlayerListSchema
-				if errRead := json.NewDecoder(input).Decode(&localPlayersList); errRead != nil {
-					slog.Error("Failed to parse local player list", errAttr(errRead))
-				} else {
-					count, errPlayerImport := rulesEngine.ImportPlayers(&localPlayersList)
-					if errPlayerImport != nil {
-						slog.Error("Failed to import local player list", errAttr(errPlayerImport))
-					} else {
-						slog.Info("Loaded local player list", slog.Int("count", count))
-					}
-				}
-
-				LogClose(input)
+				slog.Info("Loaded local player list", slog.Int("count", count))
 			}
 		}
 
 		// Try and load our existing custom rules
-		if platform.Exists(sm.LocalRulesListPath()) {
-			input, errInput := os.Open(sm.LocalRulesListPath())
-			if errInput != nil {
-				slog.Error("Failed to open local rules list", errAttr(errInput))
-			} else {
-				var localRules rules.RuleSchema
-				if errRead := json.NewDecoder(input).Decode(&localRules); errRead != nil {
-					slog.Error("Failed to parse local rules list", errAttr(errRead))
-				} else {
-					count, errRulesImport := rulesEngine.ImportRules(&localRules)
-					if errRulesImport != nil {
-						slog.Error("Failed to import local rules list", errAttr(errRulesImport))
-					}
-
-					slog.Debug("Loaded local rules list", slog.Int("count", count))
-				}
-
-				LogClose(input)
+		var localRules rules.RuleSchema
+
+		if _, errRead := readRecoverable(sm.LocalRulesListPath(), localListBackupCount, &localRules); errRead != nil {
+			if !errors.Is(errRead, os.ErrNotExist) {
+				slog.Error("Failed to read local rules list", errAttr(errRead))
 			}
+		} else {
+			count, errRulesImport := rulesEngine.ImportRules(&localRules)
+			if errRulesImport != nil {
+				slog.Error("Failed to import local rules list", errAttr(errRulesImport))
+			}
+
+			slog.Debug("Loaded local rules list", slog.Int("count", count))
 		}
 	}
 
@@ -119,14 +110,18 @@ func run() int {
 
 	rcon := newRconConnection(settings.Rcon.String(), settings.Rcon.Password)
 
-	state := newGameState(db, settingsMgr, newPlayerStates(), rcon, db)
+	re := createRulesEngine(settingsMgr)
+	local := newLocalLists(re, settingsMgr)
+
+	state := newGameState(db, settingsMgr, newPlayerStates(), rcon, db, re, local)
 
 	parser := newLogParser()
 	broadcaster := newEventBroadcaster()
 
 	var logSrc backgroundService
 	if settings.UDPListenerEnabled {
-		ingest, errListener := newUDPListener(settings.UDPListenerAddr, parser, broadcaster)
+		ingest, errListener := newUDPListener(settings.UDPListenerAddr, settings.UDPListenerSecret,
+			settings.UDPListenerAllowPlain, settings.UDPListenerPlainSources, parser, broadcaster)
 		if errListener != nil {
 			slog.Error("failed to start udp log listener", errAttr(errListener))
 			return 1
@@ -142,6 +137,7 @@ func run() int {
 	}
 
 	cr := newChatRecorder(db, broadcaster)
+	spam := newSpamDetector(settingsMgr, broadcaster, state.detectionChan)
 
 	broadcaster.registerConsumer(state.eventChan, EvtAny)
 
@@ -151,22 +147,21 @@ func run() int {
 		return 1
 	}
 
-	re := createRulesEngine(settingsMgr)
-
 	cache, cacheErr := NewCache(settingsMgr.ConfigRoot(), DurationCacheTimeout)
 	if cacheErr != nil {
 		slog.Error("Failed to set up cache", errAttr(cacheErr))
 		return 1
 	}
 
-	lm := newListManager(cache, re, settingsMgr)
-	updater := newPlayerDataLoader(db, dataSource, settingsMgr, re, state.profileUpdateQueue, state.playerDataChan)
+	lm := newListManager(cache, re, settingsMgr, local)
+	updater := newPlayerDataLoader(db, dataSource, settingsMgr, re, newAvatarLoader(cache, re, avatarCDNURL),
+		state.profileUpdateQueue, state.playerDataChan)
 	discordPresence := newDiscordState(state, settingsMgr)
 	processHandler := newProcessState(plat, rcon, settingsMgr)
 	statusHandler := newStatusUpdater(rcon, processHandler, state, time.Second*2)
 	bigBrotherHandler := newOverwatch(settingsMgr, rcon, state)
 
-	mux, errRoutes := createHandlers(db, state, processHandler, settingsMgr, re, rcon)
+	mux, errRoutes := createHandlers(db, state, processHandler, settingsMgr, re, rcon, lm, local)
 	if errRoutes != nil {
 		slog.Error("failed to create http handlers", errAttr(errRoutes))
 	}
@@ -177,7 +172,7 @@ func run() int {
 	httpServer := newHTTPServer(ctx, settings.HTTPListenAddr, mux)
 
 	// Start all the background workers
-	for _, svc := range []backgroundService{discordPresence, cr, logSrc, updater, statusHandler, bigBrotherHandler, processHandler, state, lm} {
+	for _, svc := range []backgroundService{discordPresence, cr, spam, logSrc, updater, statusHandler, bigBrotherHandler, processHandler, state, lm, local} {
 		go svc.start(ctx)
 	}
 
@@ -208,6 +203,9 @@ func run() int {
 
 	<-ctx.Done()
 
+	// Ensure any pending changes are written before exiting
+	local.flush()
+
 	timeout, cancelHTTP := context.WithTimeout(context.Background(), time.Second*15)
 	defer cancelHTTP()
 
@@ -221,5 +219,9 @@ func run() int {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		os.Exit(runReplay(os.Args[2:]))
+	}
+
 	os.Exit(run())
 }
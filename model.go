@@ -36,6 +36,77 @@ func (t Team) String() string {
 	}
 }
 
+// parseTeam converts the team names used in the console into a Team.
+func parseTeam(name string) Team {
+	switch strings.ToLower(name) {
+	case "red":
+		return Red
+	case "blu", "blue":
+		return Blu
+	case "spectator", "spectators":
+		return Spec
+	default:
+		return Unassigned
+	}
+}
+
+type PlayerClass int
+
+const (
+	ClassUnknown PlayerClass = iota
+	Scout
+	Sniper
+	Soldier
+	Demoman
+	Medic
+	Heavy
+	Pyro
+	Spy
+	Engineer
+)
+
+func (c PlayerClass) String() string {
+	switch c {
+	case Scout:
+		return "scout"
+	case Sniper:
+		return "sniper"
+	case Soldier:
+		return "soldier"
+	case Demoman:
+		return "demoman"
+	case Medic:
+		return "medic"
+	case Heavy:
+		return "heavy"
+	case Pyro:
+		return "pyro"
+	case Spy:
+		return "spy"
+	case Engineer:
+		return "engineer"
+	case ClassUnknown:
+		return "unknown"
+	default:
+		return "unknown"
+	}
+}
+
+// parsePlayerClass converts the class names used in the console into a PlayerClass.
+func parsePlayerClass(name string) PlayerClass {
+	for class := Scout; class <= Engineer; class++ {
+		if strings.EqualFold(name, class.String()) {
+			return class
+		}
+	}
+
+	if strings.EqualFold(name, "heavyweapons") {
+		return Heavy
+	}
+
+	return ClassUnknown
+}
+
 type BaseSID struct {
 	SteamID steamid.SteamID `json:"steam_id"`
 }
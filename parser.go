@@ -53,6 +53,13 @@ type LogEvent struct {
 	MetaData        string
 	Dead            bool
 	TeamOnly        bool
+	// Kill feed details. Assister is only set when the kill was assisted, and Weapon is weaponWorld for
+	// environmental deaths.
+	Assister string
+	Weapon   string
+	Crit     bool
+	// Class is the class we will spawn as. The console only reports our own class changes.
+	Class PlayerClass
 }
 
 func (e *LogEvent) ApplyTimestamp(tsString string) error {
@@ -110,9 +117,23 @@ func (ut updateType) String() string {
 	}
 }
 
+// killEvent is a single kill feed entry. Suicides and environmental deaths have the victim as the source.
 type killEvent struct {
-	sourceName string
-	victimName string
+	sourceName   string
+	victimName   string
+	assisterName string
+	weapon       string
+	crit         bool
+	suicide      bool
+}
+
+type teamEvent struct {
+	name string
+	team Team
+}
+
+type classEvent struct {
+	class PlayerClass
 }
 
 type statusEvent struct {
@@ -153,6 +174,12 @@ type logParser struct {
 	deadPrefix     = "*DEAD* "
 	deadTeamPrefix = "*DEAD*(TEAM) "
 	// coachPrefix    = "*COACH* ".
+
+	// assistSeparator separates the killer and assister names in the kill feed.
+	assistSeparator = " + "
+	critSuffix      = ". (crit)"
+	// weaponWorld is used as the weapon for deaths not caused by a player, such as falling or map hazards.
+	weaponWorld = "world"
 )
 
 func newLogParser() *logParser {
@@ -168,6 +195,9 @@ func newLogParser() *logParser {
 			regexp.MustCompile(`^(?P<dt>[01]\d/[0123]\d/20\d{2}\s-\s\d{2}:\d{2}:\d{2}):\stags\s{4}:\s(.+?)$`),
 			regexp.MustCompile(`^(?P<dt>[01]\d/[0123]\d/20\d{2}\s-\s\d{2}:\d{2}:\d{2}):\sudp/ip\s{2}:\s(\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}:\d{1,5})$`),
 			regexp.MustCompile(`^\s{2}(Member|Pending)\[\d+]\s+(?P<sid>\[.+?]).+?TF_GC_TEAM_(?P<team>(DEFENDERS|INVADERS))\s{2}type\s=\sMATCH_PLAYER$`),
+			regexp.MustCompile(`^(?P<dt>[01]\d/[0123]\d/20\d{2}\s-\s\d{2}:\d{2}:\d{2}):\s(.+?)\s(suicided|died)\.$`),
+			regexp.MustCompile(`^(?P<dt>[01]\d/[0123]\d/20\d{2}\s-\s\d{2}:\d{2}:\d{2}):\sPlayer\s(.+?)\sjoined\steam\s(?P<team>RED|BLU|Spectators?)$`),
+			regexp.MustCompile(`^(?P<dt>[01]\d/[0123]\d/20\d{2}\s-\s\d{2}:\d{2}:\d{2}):\s\*You\s(will\srespawn|spawned)\sas\s(?P<class>\w+)$`),
 		},
 	}
 }
@@ -239,8 +269,21 @@ func (parser *logParser) parse(msg string, outEvent *LogEvent) error {
 				outEvent.PlayerConnected = dur
 				outEvent.PlayerPing = int(ping)
 			case EvtKill:
-				outEvent.Player = match[2]
+				outEvent.Player, outEvent.Assister, _ = strings.Cut(match[2], assistSeparator)
 				outEvent.Victim = match[3]
+				outEvent.Weapon = match[4]
+				outEvent.Crit = match[5] == critSuffix
+			case EvtSuicide:
+				outEvent.Player = match[2]
+				outEvent.Victim = match[2]
+				if match[3] == "died" {
+					outEvent.Weapon = weaponWorld
+				}
+			case EvtTeam:
+				outEvent.Player = match[2]
+				outEvent.Team = parseTeam(match[3])
+			case EvtClass:
+				outEvent.Class = parsePlayerClass(match[3])
 			case EvtHostname:
 				outEvent.MetaData = match[2]
 			case EvtMap:
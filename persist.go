@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/leighmacdonald/bd/rules"
+)
+
+const (
+	// localListBackupCount is the number of previous versions of each local list that are kept.
+	localListBackupCount = 3
+	// localListSaveDelay is how long to wait for further changes before writing the local lists, so that a burst
+	// of marks only results in a single write.
+	localListSaveDelay = time.Second * 2
+)
+
+// localLists handles persisting the local player and rules lists to disk. Changes are debounced and written
+// atomically by writing to a temporary file and renaming it over the existing list once complete. The previous
+// versions are kept as rotating backups which are used to recover if the current list is corrupted.
+type localLists struct {
+	re          *rules.Engine
+	settingsMgr *settingsManager
+	changed     chan struct{}
+	mu          *sync.Mutex
+	players     bool
+	rules       bool
+}
+
+func newLocalLists(re *rules.Engine, settingsMgr *settingsManager) *localLists {
+	return &localLists{
+		re:          re,
+		settingsMgr: settingsMgr,
+		changed:     make(chan struct{}, 1),
+		mu:          &sync.Mutex{},
+	}
+}
+
+func (l *localLists) start(ctx context.Context) {
+	timer := time.NewTimer(localListSaveDelay)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			l.flush()
+
+			return
+		case <-l.changed:
+			timer.Reset(localListSaveDelay)
+		case <-timer.C:
+			l.flush()
+		}
+	}
+}
+
+// savePlayers schedules the local player list to be written.
+func (l *localLists) savePlayers() {
+	l.mu.Lock()
+	l.players = true
+	l.mu.Unlock()
+
+	l.notify()
+}
+
+// saveRules schedules the local rules list to be written.
+func (l *localLists) saveRules() {
+	l.mu.Lock()
+	l.rules = true
+	l.mu.Unlock()
+
+	l.notify()
+}
+
+func (l *localLists) notify() {
+	select {
+	case l.changed <- struct{}{}:
+	default:
+		// A save is already pending and will include these changes.
+	}
+}
+
+// flush immediately writes any of the lists with pending changes.
+func (l *localLists) flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.players {
+		listPath := l.settingsMgr.LocalPlayerListPath()
+		if errWrite := writeAtomic(listPath, localListBackupCount, func(w io.Writer) error {
+			return l.re.ExportPlayers(rules.LocalRuleName, rules.DetectFormat(listPath, nil), w)
+		}); errWrite != nil {
+			slog.Error("Failed to save local player list", errAttr(errWrite))
+		} else {
+			l.players = false
+		}
+	}
+
+	if l.rules {
+		listPath := l.settingsMgr.LocalRulesListPath()
+		if errWrite := writeAtomic(listPath, localListBackupCount, func(w io.Writer) error {
+			return l.re.ExportRules(rules.LocalRuleName, rules.DetectFormat(listPath, nil), w)
+		}); errWrite != nil {
+			slog.Error("Failed to save local rules list", errAttr(errWrite))
+		} else {
+			l.rules = false
+		}
+	}
+}
+
+func backupPath(path string, index int) string {
+	return fmt.Sprintf("%s.%d.bak", path, index)
+}
+
+// writeAtomic writes the output of the write function to a temporary file in the same directory as the path, which
+// replaces the existing file only once it has been completely written. The existing file is kept as the newest of
+// the rotating backups.
+func writeAtomic(path string, backups int, write func(w io.Writer) error) error {
+	temp, errTemp := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if errTemp != nil {
+		return errors.Join(errTemp, errLocalListWrite)
+	}
+
+	tempPath := temp.Name()
+
+	defer func() {
+		// Does nothing once the temp file has been renamed
+		_ = os.Remove(tempPath)
+	}()
+
+	if errWrite := write(temp); errWrite != nil {
+		LogClose(temp)
+
+		return errors.Join(errWrite, errLocalListWrite)
+	}
+
+	if errSync := temp.Sync(); errSync != nil {
+		LogClose(temp)
+
+		return errors.Join(errSync, errLocalListWrite)
+	}
+
+	if errClose := temp.Close(); errClose != nil {
+		return errors.Join(errClose, errLocalListWrite)
+	}
+
+	if errRotate := rotateBackups(path, backups); errRotate != nil {
+		return errRotate
+	}
+
+	if errRename := os.Rename(tempPath, path); errRename != nil {
+		return errors.Join(errRename, errLocalListWrite)
+	}
+
+	return nil
+}
+
+// rotateBackups shifts each of the existing backups back one position, discarding the oldest, and copies the current
+// file into the newest backup position. The current file is copied rather than moved so that it always exists.
+func rotateBackups(path string, backups int) error {
+	if backups <= 0 {
+		return nil
+	}
+
+	current, errRead := os.ReadFile(path)
+	if errRead != nil {
+		if errors.Is(errRead, os.ErrNotExist) {
+			return nil
+		}
+
+		return errors.Join(errRead, errLocalListBackup)
+	}
+
+	for index := backups - 1; index > 0; index-- {
+		if errRename := os.Rename(backupPath(path, index), backupPath(path, index+1)); errRename != nil &&
+			!errors.Is(errRename, os.ErrNotExist) {
+			return errors.Join(errRename, errLocalListBackup)
+		}
+	}
+
+	if errWrite := os.WriteFile(backupPath(path, 1), current, 0o600); errWrite != nil {
+		return errors.Join(errWrite, errLocalListBackup)
+	}
+
+	return nil
+}
+
+// readRecoverable decodes the json or yaml list file into the target. If the file cannot be read or decoded, each of
+// the backups is tried from newest to oldest. When a backup is used, the corrupt file is moved aside and replaced
+// with the contents of the backup. The path that was successfully read is returned.
+//
+// Each file is decoded into a new value which is only copied into the target once it has been decoded successfully,
+// so a partially decoded file never leaves its values in the target.
+func readRecoverable[T any](path string, backups int, target *T) (string, error) {
+	var current T
+
+	errRead := decodeListFile(path, &current)
+	if errRead == nil {
+		*target = current
+	}
+
+	if errRead == nil || errors.Is(errRead, os.ErrNotExist) {
+		return path, errRead
+	}
+
+	slog.Warn("Local list is corrupt, trying backups", slog.String("path", path), errAttr(errRead))
+
+	for index := 1; index <= backups; index++ {
+		var (
+			candidate = backupPath(path, index)
+			backup    T
+		)
+
+		if errBackup := decodeListFile(candidate, &backup); errBackup != nil {
+			continue
+		}
+
+		*target = backup
+
+		if errRename := os.Rename(path, path+".corrupt"); errRename != nil {
+			slog.Error("Failed to move corrupt local list", slog.String("path", path), errAttr(errRename))
+		}
+
+		// Restore the backup so the list is still found if the app exits before it is next saved
+		if errRestore := writeAtomic(path, 0, func(w io.Writer) error {
+			return rules.EncodeList(w, rules.DetectFormat(path, nil), target)
+		}); errRestore != nil {
+			slog.Error("Failed to restore local list from backup", slog.String("path", path), errAttr(errRestore))
+		}
+
+		slog.Warn("Recovered local list from backup", slog.String("path", candidate))
+
+		return candidate, nil
+	}
+
+	return path, errors.Join(errRead, errLocalListRecover)
+}
+
+// decodeListFile reads and decodes the entire list file into the target. Backups do not have a known extension, so
+// their format is detected from their content.
+func decodeListFile(path string, target any) error {
+	body, errRead := os.ReadFile(path)
+	if errRead != nil {
+		return errRead
+	}
+
+	return rules.DecodeList(body, rules.DetectFormat(path, body), target)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leighmacdonald/bd/rules"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalListPersistence(t *testing.T) {
+	var (
+		path     = filepath.Join(t.TempDir(), "playerlist.local.json")
+		playerID = steamid.New(76561197961279983)
+		engine   = rules.New()
+	)
+
+	// Write more versions than there are backups so that the oldest are rotated out
+	for version := 1; version <= localListBackupCount+2; version++ {
+		require.NoError(t, writeAtomic(path, localListBackupCount, func(w io.Writer) error {
+			_, errWrite := fmt.Fprintf(w, `{"file_info": {"title": "local", "description": "v%d"}, "players": []}`, version)
+
+			return errWrite
+		}))
+	}
+
+	for index := 1; index <= localListBackupCount; index++ {
+		require.FileExists(t, backupPath(path, index))
+	}
+
+	require.NoFileExists(t, backupPath(path, localListBackupCount+1))
+
+	matches, _ := filepath.Glob(path + ".*.tmp")
+	require.Empty(t, matches)
+
+	require.NoError(t, engine.Mark(rules.MarkOpts{SteamID: playerID, Attributes: []string{"cheater"}}))
+	require.NoError(t, writeAtomic(path, localListBackupCount, func(w io.Writer) error {
+		return engine.ExportPlayers(rules.LocalRuleName, rules.FormatJSON, w)
+	}))
+
+	var current rules.PlayerListSchema
+
+	readPath, errRead := readRecoverable(path, localListBackupCount, &current)
+	require.NoError(t, errRead)
+	require.Equal(t, path, readPath)
+	require.Len(t, current.Players, 1)
+
+	// A half written file falls back to the newest valid backup
+	require.NoError(t, os.WriteFile(path, []byte(`{"file_info": {"title": "lo`), 0o600))
+	require.NoError(t, os.WriteFile(backupPath(path, 1), []byte(`not json`), 0o600))
+
+	var recovered rules.PlayerListSchema
+
+	recoveredPath, errRecover := readRecoverable(path, localListBackupCount, &recovered)
+	require.NoError(t, errRecover)
+	require.Equal(t, backupPath(path, 2), recoveredPath)
+	require.Equal(t, fmt.Sprintf("v%d", localListBackupCount+1), recovered.FileInfo.Description)
+	require.FileExists(t, path+".corrupt")
+
+	// The recovered list is restored so it can be read directly
+	var restored rules.PlayerListSchema
+
+	restoredPath, errRestored := readRecoverable(path, localListBackupCount, &restored)
+	require.NoError(t, errRestored)
+	require.Equal(t, path, restoredPath)
+	require.Equal(t, recovered.FileInfo, restored.FileInfo)
+
+	// Values from a backup that only partially decodes are not left in the target
+	require.NoError(t, os.WriteFile(path, []byte(`not json`), 0o600))
+	require.NoError(t, os.WriteFile(backupPath(path, 1), []byte(`{"file_info": {"title": "partial"}, "players": "invalid"}`), 0o600))
+	require.NoError(t, os.WriteFile(backupPath(path, 2), []byte(`{"file_info": {"description": "valid"}, "players": []}`), 0o600))
+
+	var partial rules.PlayerListSchema
+
+	partialPath, errPartial := readRecoverable(path, localListBackupCount, &partial)
+	require.NoError(t, errPartial)
+	require.Equal(t, backupPath(path, 2), partialPath)
+	require.Equal(t, rules.FileInfo{Description: "valid"}, partial.FileInfo)
+
+	// Missing lists are not an error that requires recovery
+	_, errMissing := readRecoverable(filepath.Join(t.TempDir(), "missing.json"), localListBackupCount, &restored)
+	require.ErrorIs(t, errMissing, os.ErrNotExist)
+}
+
+func TestLocalRulesSaved(t *testing.T) {
+	var (
+		engine      = rules.New()
+		settingsMgr = newTestSettingsManager(t)
+		local       = newLocalLists(engine, settingsMgr)
+		rule        = rules.RuleDefinition{
+			Description: "bot names",
+			Triggers: rules.RuleTriggers{
+				UsernameTextMatch: &rules.RuleTriggerNameMatch{Mode: rules.TextMatchModeContains, Patterns: []string{"bot"}},
+			},
+		}
+	)
+
+	readRules := func() []rules.RuleDefinition {
+		var saved rules.RuleSchema
+
+		_, errRead := readRecoverable(settingsMgr.LocalRulesListPath(), localListBackupCount, &saved)
+		require.NoError(t, errRead)
+
+		return saved.Rules
+	}
+
+	index, errAdd := engine.AddRule(rule)
+	require.NoError(t, errAdd)
+
+	local.saveRules()
+	local.flush()
+	require.Len(t, readRules(), 1)
+	require.Equal(t, "bot names", readRules()[0].Description)
+
+	rule.Description = "cheater names"
+	require.NoError(t, engine.UpdateRule(index, rule))
+
+	local.saveRules()
+	local.flush()
+	require.Equal(t, "cheater names", readRules()[0].Description)
+
+	require.NoError(t, engine.DeleteRule(index))
+
+	local.saveRules()
+	local.flush()
+	require.Empty(t, readRules())
+}
@@ -34,11 +34,15 @@ type PlayerState struct {
 	UpdatedOn        time.Time       `json:"updated_on"`
 
 	EconomyBan steamweb.EconBanState `json:"economy_ban"`
+	// AvatarPerceptualHash is computed when the avatar is downloaded and is not stored in the database
+	AvatarPerceptualHash string `json:"avatar_perceptual_hash"`
 
 	// - Parsed Ephemeral data
 
-	// tf_lobby_debug
+	// tf_lobby_debug, g15_dumpplayer and team changes in the console
 	Team Team `json:"team"`
+	// Class is only known for our own player
+	Class PlayerClass `json:"class"`
 
 	// status
 	// Connected is how long the user has been in the server
@@ -60,6 +64,12 @@ type PlayerState struct {
 	Deaths      int  `json:"deaths"`
 	Kills       int  `json:"kills"`
 
+	// console kill feed
+	Assists  int `json:"assists"`
+	Suicides int `json:"suicides"`
+	// Weapons holds the kills made with each weapon on the current server
+	Weapons map[string]WeaponStats `json:"weapons"`
+
 	// Misc
 	KPM float64 `json:"kpm"`
 	// Incremented on each kick attempt. Used to cycle through and not attempt the same bot
@@ -71,6 +81,46 @@ type PlayerState struct {
 	OurFriend            bool                 `json:"our_friend"`
 	Sourcebans           []models.SbBanRecord `json:"sourcebans"`
 	Matches              []rules.MatchResult  `json:"matches"`
+	// TransientMarks are attributes applied by rule matches which only last for the current session
+	TransientMarks []string `json:"transient_marks"`
+	// Suspicion is the combined score of the players matches, bans and account age
+	Suspicion rules.SuspicionScore `json:"suspicion"`
+}
+
+// WeaponStats tracks the kills a player has made using a single weapon.
+type WeaponStats struct {
+	Kills int `json:"kills"`
+	Crits int `json:"crits"`
+}
+
+// suspicionInput returns all the known values that contribute to the players suspicion score.
+func (ps PlayerState) suspicionInput() rules.SuspicionInput {
+	return rules.SuspicionInput{
+		Matches:          ps.Matches,
+		VACBans:          int(ps.VacBans),
+		GameBans:         int(ps.GameBans),
+		CommunityBanned:  ps.CommunityBanned,
+		EconomyBanned:    ps.EconomyBan != "" && ps.EconomyBan != steamweb.EconBanNone,
+		Sourcebans:       len(ps.Sourcebans),
+		AccountCreatedOn: ps.AccountCreatedOn,
+	}
+}
+
+// hasMatch returns true if an equivalent match has already been recorded for the player.
+func (ps PlayerState) hasMatch(match rules.MatchResult) bool {
+	for _, known := range ps.Matches {
+		if sameMatch(known, match) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sameMatch returns true if both matches were produced by the same rule or list entry.
+func sameMatch(a rules.MatchResult, b rules.MatchResult) bool {
+	return a.Origin == b.Origin && a.MatcherType == b.MatcherType && a.Pattern == b.Pattern &&
+		a.Description == b.Description
 }
 
 func (ps PlayerState) MatchAttr(tags []string) bool {
@@ -114,6 +164,8 @@ func newPlayer(sid64 steamid.SteamID, name string) PlayerState {
 		UpdatedOn:        curTIme,
 		ProfileUpdatedOn: curTIme.AddDate(-1, 0, 0),
 		Matches:          rules.MatchResults{},
+		TransientMarks:   []string{},
+		Suspicion:        rules.SuspicionScore{Breakdown: []rules.SuspicionComponent{}},
 	}
 }
 
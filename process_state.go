@@ -51,7 +51,8 @@ func (p *processState) launchGame(settingsMgr *settingsManager) {
 		settingsMgr.locateSteamDir(),
 		settings.SteamID,
 		settings.UDPListenerEnabled,
-		settings.UDPListenerAddr)
+		settings.UDPListenerAddr,
+		settings.UDPListenerSecret)
 
 	if errArgs != nil {
 		slog.Error("Failed to get TF2 launch args", errAttr(errArgs))
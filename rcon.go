@@ -10,21 +10,27 @@
 	"github.com/leighmacdonald/rcon/rcon"
 )
 
-type rconConnection struct {
+// rconConnection executes commands on the game client.
+type rconConnection interface {
+	exec(ctx context.Context, cmd string, large bool) (string, error)
+}
+
+// rconClient sends commands to the game client over rcon.
+type rconClient struct {
 	addr     string
 	password string
 	timeout  time.Duration
 }
 
-func newRconConnection(addr string, password string) rconConnection {
-	return rconConnection{
+func newRconConnection(addr string, password string) rconClient {
+	return rconClient{
 		addr:     addr,
 		password: password,
 		timeout:  DurationRCONRequestTimeout,
 	}
 }
 
-func (r rconConnection) exec(ctx context.Context, cmd string, large bool) (string, error) {
+func (r rconClient) exec(ctx context.Context, cmd string, large bool) (string, error) {
 	conn, errConn := rcon.Dial(ctx, r.addr, r.password, DurationRCONRequestTimeout)
 	if errConn != nil {
 		return "", errors.Join(errConn, fmt.Errorf("%w: %s", errRCONConnect, r.addr))
@@ -43,7 +49,7 @@ func (r rconConnection) exec(ctx context.Context, cmd string, large bool) (strin
 	return r.rcon(conn, cmd)
 }
 
-func (r rconConnection) rcon(conn *rcon.RemoteConsole, cmd string) (string, error) {
+func (r rconClient) rcon(conn *rcon.RemoteConsole, cmd string) (string, error) {
 	cmdID, errWrite := conn.Write(cmd)
 	if errWrite != nil {
 		return "", errors.Join(errWrite, errRCONExec)
@@ -62,7 +68,7 @@ func (r rconConnection) rcon(conn *rcon.RemoteConsole, cmd string) (string, erro
 }
 
 // rconLarge is used for rcon responses that exceed the size of a single rcon packet (g15_dumpplayer).
-func (r rconConnection) rconLarge(conn *rcon.RemoteConsole, cmd string) (string, error) {
+func (r rconClient) rconLarge(conn *rcon.RemoteConsole, cmd string) (string, error) {
 	cmdID, errWrite := conn.Write(cmd)
 	if errWrite != nil {
 		return "", errors.Join(errWrite, errRCONExec)
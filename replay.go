@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leighmacdonald/bd/platform"
+	"github.com/leighmacdonald/bd/store"
+)
+
+const (
+	// replaySettleDelay is how long to wait after the last line is replayed for the services to finish processing it.
+	replaySettleDelay = time.Second
+	// replayListCacheAge is the max age of the cached lists used when the lists cannot be downloaded. It's much longer
+	// than normal, as a replay with old lists is still more useful than one without them.
+	replayListCacheAge = time.Hour * 24 * 365
+)
+
+// logReplay feeds the lines of an existing console log through the parser, broadcasting the events as if they were
+// being read from the running game. Lines are delayed using their timestamps, divided by the speed. A speed of 0
+// replays the log as fast as possible.
+type logReplay struct {
+	path        string
+	speed       float64
+	parser      Parser
+	broadcaster *eventBroadcaster
+	done        chan struct{}
+}
+
+func newLogReplay(path string, speed float64, parser Parser, broadcaster *eventBroadcaster) (*logReplay, error) {
+	if _, errStat := os.Stat(path); errStat != nil {
+		return nil, errors.Join(errStat, errReplayOpen)
+	}
+
+	return &logReplay{
+		path:        path,
+		speed:       speed,
+		parser:      parser,
+		broadcaster: broadcaster,
+		done:        make(chan struct{}),
+	}, nil
+}
+
+func (r *logReplay) start(ctx context.Context) {
+	defer close(r.done)
+
+	logFile, errOpen := os.Open(r.path)
+	if errOpen != nil {
+		slog.Error("Failed to open replay log", errAttr(errOpen))
+
+		return
+	}
+
+	defer LogClose(logFile)
+
+	var (
+		scanner  = bufio.NewScanner(logFile)
+		previous time.Time
+		count    int
+	)
+
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+
+		if timestamp, found := lineTimestamp(line); found {
+			if delay := replayDelay(previous, timestamp, r.speed); delay > 0 {
+				select {
+				case <-time.After(delay):
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			previous = timestamp
+		}
+
+		var logEvent LogEvent
+		if errParse := r.parser.parse(line, &logEvent); errParse != nil {
+			continue
+		}
+
+		r.broadcaster.broadcast(logEvent)
+
+		count++
+
+		if ctx.Err() != nil {
+			return
+		}
+	}
+
+	if errScan := scanner.Err(); errScan != nil {
+		slog.Error("Failed to read replay log", errAttr(errScan))
+	}
+
+	slog.Info("Replay complete", slog.String("path", r.path), slog.Int("events", count))
+}
+
+// lineTimestamp returns the timestamp at the start of the console log line, if it has one.
+func lineTimestamp(line string) (time.Time, bool) {
+	if len(line) < len(logTimestampFormat) {
+		return time.Time{}, false
+	}
+
+	timestamp, errParse := parseTimestamp(line[:len(logTimestampFormat)])
+	if errParse != nil {
+		return time.Time{}, false
+	}
+
+	return timestamp, true
+}
+
+// replayDelay returns how long to wait before replaying a line logged at current, when the previous line was logged
+// at previous.
+func replayDelay(previous time.Time, current time.Time, speed float64) time.Duration {
+	if speed <= 0 || previous.IsZero() || !current.After(previous) {
+		return 0
+	}
+
+	return time.Duration(float64(current.Sub(previous)) / speed)
+}
+
+// recordedCommand is an rcon command that was recorded instead of being sent to the game.
+type recordedCommand struct {
+	Time    time.Time
+	Command string
+}
+
+// rconRecorder is used in place of a real rcon connection for dry runs. Commands are recorded, and an empty response
+// returned, instead of being sent to the game.
+type rconRecorder struct {
+	mu       *sync.Mutex
+	commands []recordedCommand
+}
+
+func newRconRecorder() *rconRecorder {
+	return &rconRecorder{mu: &sync.Mutex{}}
+}
+
+func (r *rconRecorder) exec(_ context.Context, cmd string, _ bool) (string, error) {
+	r.mu.Lock()
+	r.commands = append(r.commands, recordedCommand{Time: time.Now(), Command: cmd})
+	r.mu.Unlock()
+
+	slog.Info("Dry run rcon command", slog.String("cmd", cmd))
+
+	return "", nil
+}
+
+// recorded returns all the commands that have been recorded.
+func (r *rconRecorder) recorded() []recordedCommand {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]recordedCommand{}, r.commands...)
+}
+
+// runReplay implements the replay subcommand, which replays a console log through the parser, game state, rules
+// engine and announcer without the game running. The enabled lists are loaded before replaying, using the cached
+// copies when they cannot be downloaded. Rcon commands are recorded rather than sent, players are stored in a
+// temporary database and the local lists are never written, so a replay has no lasting effects.
+func runReplay(args []string) int {
+	flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+	speed := flags.Float64("speed", 1, "Playback speed multiplier, 0 replays as fast as possible")
+
+	if errParse := flags.Parse(args); errParse != nil {
+		return 1
+	}
+
+	if flags.NArg() != 1 {
+		fmt.Fprintln(flags.Output(), "Usage: bd replay [-speed n] <console.log>")
+
+		return 1
+	}
+
+	settingsMgr := newSettingsManager(platform.New())
+	if errSetup := settingsMgr.setup(); errSetup != nil {
+		slog.Error("Failed to create settings directories", errAttr(errSetup))
+
+		return 1
+	}
+
+	if errSettings := settingsMgr.validateAndLoad(); errSettings != nil {
+		slog.Error("Failed to load settings", errAttr(errSettings))
+
+		return 1
+	}
+
+	tempDir, errTemp := os.MkdirTemp("", "bd-replay-*")
+	if errTemp != nil {
+		slog.Error("Failed to create temp dir", errAttr(errors.Join(errTemp, errTempDir)))
+
+		return 1
+	}
+
+	defer func() {
+		if errRemove := os.RemoveAll(tempDir); errRemove != nil {
+			slog.Error("Failed to remove temp dir", errAttr(errRemove))
+		}
+	}()
+
+	db, dbCloser, errDB := store.CreateDB(filepath.Join(tempDir, "replay.sqlite"))
+	if errDB != nil {
+		slog.Error("failed to create database", errAttr(errDB))
+
+		return 1
+	}
+
+	defer dbCloser()
+
+	cache, errCache := NewCache(settingsMgr.ConfigRoot(), replayListCacheAge)
+	if errCache != nil {
+		slog.Error("Failed to set up cache", errAttr(errCache))
+
+		return 1
+	}
+
+	var (
+		recorder    = newRconRecorder()
+		re          = createRulesEngine(settingsMgr)
+		local       = newLocalLists(re, settingsMgr)
+		state       = newGameState(db, settingsMgr, newPlayerStates(), recorder, db, re, local)
+		broadcaster = newEventBroadcaster()
+	)
+
+	replay, errReplay := newLogReplay(flags.Arg(0), *speed, newLogParser(), broadcaster)
+	if errReplay != nil {
+		slog.Error("Failed to open replay log", errAttr(errReplay))
+
+		return 1
+	}
+
+	broadcaster.registerConsumer(state.eventChan, EvtAny)
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	newListManager(cache, re, settingsMgr, local).importLists(ctx, settingsMgr.Settings().Lists)
+
+	// Profiles are not fetched while replaying, so the profile update requests are discarded
+	go func() {
+		for {
+			select {
+			case <-state.profileUpdateQueue:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for _, svc := range []backgroundService{
+		newSpamDetector(settingsMgr, broadcaster, state.detectionChan),
+		newOverwatch(settingsMgr, recorder, state),
+		state,
+		replay,
+	} {
+		go svc.start(ctx)
+	}
+
+	<-replay.done
+	time.Sleep(replaySettleDelay)
+	stop()
+
+	reportReplay(state.players.all(), recorder.recorded())
+
+	return 0
+}
+
+// reportReplay logs all the matches made against players, along with the rcon commands recorded during the replay.
+func reportReplay(players []PlayerState, commands []recordedCommand) {
+	for _, player := range players {
+		for _, match := range player.Matches {
+			slog.Info("Replay match",
+				slog.String("name", player.Personaname),
+				slog.String("steam_id", player.SteamID.String()),
+				slog.String("origin", match.Origin),
+				slog.String("matcher_type", match.MatcherType),
+				slog.String("attributes", strings.Join(match.Attributes, ",")),
+				slog.String("description", match.Description))
+		}
+	}
+
+	for _, command := range commands {
+		slog.Info("Replay rcon command", slog.String("cmd", command.Command))
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogReplay(t *testing.T) {
+	var (
+		broadcaster = newEventBroadcaster()
+		events      = make(chan LogEvent)
+		counts      = map[EventType]int{}
+	)
+
+	broadcaster.registerConsumer(events, EvtAny)
+
+	replay, errReplay := newLogReplay(filepath.Join("testdata", "console.log"), 0, newLogParser(), broadcaster)
+	require.NoError(t, errReplay)
+
+	_, errMissing := newLogReplay(filepath.Join("testdata", "missing.log"), 0, newLogParser(), broadcaster)
+	require.ErrorIs(t, errMissing, errReplayOpen)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	go replay.start(ctx)
+
+	for running := true; running; {
+		select {
+		case evt := <-events:
+			counts[evt.Type]++
+		case <-replay.done:
+			running = false
+		case <-ctx.Done():
+			t.Fatal("Timed out replaying log")
+		}
+	}
+
+	require.Positive(t, counts[EvtStatusID])
+	require.Positive(t, counts[EvtMsg])
+	require.Positive(t, counts[EvtKill])
+}
+
+func TestReplayDelay(t *testing.T) {
+	var (
+		previous = time.Date(2023, time.February, 24, 23, 37, 19, 0, time.UTC)
+		current  = previous.Add(time.Second * 10)
+	)
+
+	require.Equal(t, time.Second*10, replayDelay(previous, current, 1))
+	require.Equal(t, time.Second*5, replayDelay(previous, current, 2))
+	require.Zero(t, replayDelay(previous, current, 0))
+	require.Zero(t, replayDelay(time.Time{}, current, 1))
+	require.Zero(t, replayDelay(current, previous, 1))
+
+	timestamp, found := lineTimestamp("02/24/2023 - 23:37:19: Hassium connected")
+	require.True(t, found)
+	require.Equal(t, previous, timestamp)
+
+	_, found = lineTimestamp("  Member[0] [U:1:12345]")
+	require.False(t, found)
+}
+
+func TestRconRecorder(t *testing.T) {
+	var (
+		recorder = newRconRecorder()
+		watcher  = newOverwatch(nil, recorder, nil)
+	)
+
+	require.NoError(t, watcher.sendChat(context.Background(), ChatDestParty, "hello %s", "world"))
+
+	commands := recorder.recorded()
+	require.Len(t, commands, 1)
+	require.Equal(t, "say_party hello world", commands[0].Command)
+}
@@ -1,6 +1,7 @@
 package rules
 
 import (
+	"crypto/sha1"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -10,6 +11,7 @@
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -27,22 +29,34 @@
 	ErrUnknownRuleList   = errors.New("unknown rules list")
 	ErrInvalidRegex      = errors.New("invalid regex pattern")
 	ErrInvalidAttributes = errors.New("invalid attribute count")
+	ErrInvalidRule       = errors.New("rule has no triggers defined")
+	ErrUnknownRule       = errors.New("unknown rule")
+	ErrUnknownList       = errors.New("unknown list")
+	ErrRemoveLocalList   = errors.New("local lists cannot be removed")
 )
 
 type Engine struct {
 	rulesLists  []*RuleSchema
 	playerLists []*PlayerListSchema
 	knownTags   []string
+	steamIndex  *steamIndex
+	taxonomy    *Taxonomy
 	sync.RWMutex
 }
 
 func New() *Engine {
-	return &Engine{
+	engine := &Engine{
 		rulesLists:  []*RuleSchema{NewRuleSchema()},
 		playerLists: []*PlayerListSchema{NewPlayerListSchema()},
 		knownTags:   []string{},
+		steamIndex:  newSteamIndex(),
+		taxonomy:    newTaxonomy(DefaultAttributes()),
 		RWMutex:     sync.RWMutex{},
 	}
+
+	engine.steamIndex.rebuild(engine.playerLists)
+
+	return engine
 }
 
 const (
@@ -54,6 +68,10 @@ type MarkOpts struct {
 	Attributes []string
 	Proof      []string
 	Name       string
+	// Expires is when the marked attributes expire. The zero value never expires.
+	Expires time.Time
+	// AttributeExpiry overrides Expires for individual attributes.
+	AttributeExpiry map[string]time.Time
 }
 
 // FindNewestEntries will scan all loaded lists and return the most recent matches as determined by the last seen attr.
@@ -65,6 +83,9 @@ func (e *Engine) FindNewestEntries(max int, validAttrs []string) steamid.Collect
 
 	var matchers []SteamIDMatcherHandler
 
+	// List entries are not canonicalised, so they must be checked against every alias as well.
+	validAttrs = e.taxonomy.Expand(validAttrs)
+
 	for _, list := range e.playerLists {
 		for _, m := range list.matchersSteam {
 			if m.HasOneOfAttr(validAttrs...) {
@@ -92,7 +113,7 @@ func (e *Engine) FindNewestEntries(max int, validAttrs []string) steamid.Collect
 
 func (e *Engine) UserPlayerList() *PlayerListSchema {
 	for _, list := range e.playerLists {
-		if list.FileInfo.Title == LocalRuleName {
+		if list.isLocal() {
 			return list
 		}
 	}
@@ -102,7 +123,7 @@ func (e *Engine) UserPlayerList() *PlayerListSchema {
 
 func (e *Engine) UserRuleList() *RuleSchema {
 	for _, list := range e.rulesLists {
-		if list.FileInfo.Title == LocalRuleName {
+		if list.isLocal() {
 			return list
 		}
 	}
@@ -137,23 +158,13 @@ func (e *Engine) Unmark(steamID steamid.SteamID) bool {
 
 	list.Players = players
 
-	var (
-		userList      = e.UserPlayerList()
-		validMatchers []SteamIDMatcherHandler
-	)
-
-	for _, matcher := range userList.matchersSteam {
-		if _, matchFound := matcher.Match(steamID); matchFound {
-			validMatchers = append(validMatchers, matcher)
-		}
-	}
-
-	userList.matchersSteam = validMatchers
+	list.unregisterSteamID(steamID)
 
 	return found
 }
 
-// Mark a player on the local player list.
+// Mark a player on the local player list. Attributes the player is already marked with are only updated if they
+// have expired.
 func (e *Engine) Mark(opts MarkOpts) error {
 	if len(opts.Attributes) == 0 {
 		return ErrInvalidAttributes
@@ -163,72 +174,105 @@ func (e *Engine) Mark(opts MarkOpts) error {
 	defer e.Unlock()
 
 	var (
-		updatedAttributes = false
-		userList          = e.UserPlayerList()
+		now      = time.Now()
+		userList = e.UserPlayerList()
 	)
 
-	for idx, knownPlayer := range userList.Players {
-		if !knownPlayer.SteamID.Valid() {
-			continue
-		}
+	idx := slices.IndexFunc(userList.Players, func(player PlayerDefinition) bool {
+		return player.SteamID.Valid() && player.SteamID == opts.SteamID
+	})
 
-		if knownPlayer.SteamID == opts.SteamID {
-			var newAttr []string
+	if idx < 0 {
+		player := PlayerDefinition{
+			Attributes: opts.Attributes,
+			LastSeen: PlayerLastSeen{
+				Time:       now.Unix(),
+				PlayerName: opts.Name,
+			},
+			SteamID: opts.SteamID,
+			Proof:   opts.Proof,
+		}
 
-			for _, updatedAttr := range opts.Attributes {
-				isNew := true
+		player.applyExpiry(opts, opts.Attributes)
 
-				for _, existingAttr := range knownPlayer.Attributes {
-					if strings.EqualFold(updatedAttr, existingAttr) {
-						isNew = false
+		userList.Players = append(userList.Players, player)
+		userList.RegisterSteamIDMatcher(newPlayerSteamIDMatcher(LocalRuleName, player))
 
-						break
-					}
-				}
+		return nil
+	}
 
-				if isNew {
-					newAttr = append(newAttr, updatedAttr)
-				}
-			}
+	var (
+		player  = &userList.Players[idx]
+		active  = player.activeAttributes(now)
+		updated []string
+	)
 
-			if len(newAttr) == 0 {
-				return ErrDuplicateSteamID
-			}
+	for _, attr := range opts.Attributes {
+		if slices.ContainsFunc(active, func(s string) bool { return strings.EqualFold(attr, s) }) {
+			continue
+		}
 
-			userList.Players[idx].Attributes = append(userList.Players[idx].Attributes, newAttr...)
-			updatedAttributes = true
+		// Expired attributes are renewed using their existing name
+		existing := slices.IndexFunc(player.Attributes, func(s string) bool { return strings.EqualFold(attr, s) })
+		if existing >= 0 {
+			attr = player.Attributes[existing]
+		} else {
+			player.Attributes = append(player.Attributes, attr)
 		}
-	}
 
-	if !updatedAttributes {
-		userList.Players = append(userList.Players, PlayerDefinition{
-			Attributes: opts.Attributes,
-			LastSeen: PlayerLastSeen{
-				Time:       time.Now().Unix(),
-				PlayerName: opts.Name,
-			},
-			SteamID: opts.SteamID,
-			Proof:   opts.Proof,
-		})
+		updated = append(updated, attr)
 	}
 
-	if !updatedAttributes {
-		userList.RegisterSteamIDMatcher(NewSteamIDMatcher(LocalRuleName, opts.SteamID, opts.Attributes))
+	if len(updated) == 0 {
+		return ErrDuplicateSteamID
 	}
 
+	player.applyExpiry(opts, updated)
+
+	// Replace the existing matcher so that it reflects the updated attributes
+	userList.unregisterSteamID(opts.SteamID)
+	userList.RegisterSteamIDMatcher(newPlayerSteamIDMatcher(LocalRuleName, *player))
+
 	return nil
 }
 
-// UniqueTags returns a list of the unique known tags across all player lists.
+// UniqueTags returns a list of the unique known tags across all player lists, in their canonical forms.
 func (e *Engine) UniqueTags() []string {
 	e.RLock()
 	defer e.RUnlock()
 
-	if e.knownTags == nil {
-		return []string{}
+	return append([]string{}, e.taxonomy.CanonicalAll(e.knownTags)...)
+}
+
+// SetTaxonomy replaces the attribute taxonomy used to canonicalise match results.
+func (e *Engine) SetTaxonomy(definitions []AttributeDefinition) error {
+	taxonomy, errTaxonomy := NewTaxonomy(definitions)
+	if errTaxonomy != nil {
+		return errTaxonomy
+	}
+
+	e.Lock()
+	e.taxonomy = taxonomy
+	e.Unlock()
+
+	return nil
+}
+
+// Taxonomy returns the current attribute taxonomy.
+func (e *Engine) Taxonomy() *Taxonomy {
+	e.RLock()
+	defer e.RUnlock()
+
+	return e.taxonomy
+}
+
+// canonicalResults rewrites the attributes of all the results into their canonical forms.
+func (e *Engine) canonicalResults(results []MatchResult) []MatchResult {
+	for index := range results {
+		results[index] = e.taxonomy.canonicalResult(results[index])
 	}
 
-	return e.knownTags
+	return results
 }
 
 func newJSONPrettyEncoder(w io.Writer) *json.Encoder {
@@ -238,14 +282,14 @@ func newJSONPrettyEncoder(w io.Writer) *json.Encoder {
 	return enc
 }
 
-// ExportPlayers writes the json encoded player list matching the listName provided to the io.Writer.
-func (e *Engine) ExportPlayers(listName string, writer io.Writer) error {
+// ExportPlayers writes the player list matching the listName provided to the io.Writer, encoded in the format.
+func (e *Engine) ExportPlayers(listName string, format ListFormat, writer io.Writer) error {
 	e.RLock()
 	defer e.RUnlock()
 
 	for _, pl := range e.playerLists {
 		if listName == pl.FileInfo.Title {
-			if errEncode := newJSONPrettyEncoder(writer).Encode(pl); errEncode != nil {
+			if errEncode := EncodeList(writer, format, pl); errEncode != nil {
 				return errors.Join(errEncode, ErrEncodePlayers)
 			}
 
@@ -256,14 +300,14 @@ func (e *Engine) ExportPlayers(listName string, writer io.Writer) error {
 	return fmt.Errorf("%w: %s", ErrUnknownPlayerList, listName)
 }
 
-// ExportRules writes the json encoded rules list matching the listName provided to the io.Writer.
-func (e *Engine) ExportRules(listName string, writer io.Writer) error {
+// ExportRules writes the rules list matching the listName provided to the io.Writer, encoded in the format.
+func (e *Engine) ExportRules(listName string, format ListFormat, writer io.Writer) error {
 	e.RLock()
 	defer e.RUnlock()
 
 	for _, pl := range e.rulesLists {
 		if listName == pl.FileInfo.Title {
-			if errEncode := newJSONPrettyEncoder(writer).Encode(pl); errEncode != nil {
+			if errEncode := EncodeList(writer, format, pl); errEncode != nil {
 				return errors.Join(errEncode, ErrEncodeRules)
 			}
 
@@ -274,80 +318,370 @@ func (e *Engine) ExportRules(listName string, writer io.Writer) error {
 	return fmt.Errorf("%w: %s", ErrUnknownRuleList, listName)
 }
 
-// ImportRules loads the provided ruleset for use.
+// ImportRules loads the provided ruleset for use. Any existing list with the same source url is replaced, as is the
+// local list when importing the local list.
+// Lists in either json or yaml can be decoded for import using DecodeList.
+//
+// Rules using RuleTriggerModeMatchAll with more than one type of trigger defined are registered as a single unit
+// so that they only trigger when all of their triggers match. All other rules have their triggers registered
+// individually, which is equivalent to RuleTriggerModeMatchAny.
+//
+// Rules that fail to load, such as those with invalid regex patterns, are skipped. The errors for each of these
+// are returned along with the count of successfully loaded triggers.
 func (e *Engine) ImportRules(list *RuleSchema) (int, error) {
-	count := 0
+	count, errImport := list.rebuildMatchers()
 
-	for _, rule := range list.Rules {
-		if rule.Triggers.UsernameTextMatch != nil {
-			attrs := rule.Triggers.UsernameTextMatch.Attributes
-			if len(attrs) == 0 {
-				attrs = append(attrs, "trigger_name")
-			}
+	e.Lock()
+	defer e.Unlock()
 
-			list.RegisterTextMatcher(NewGeneralTextMatcher(
-				list.FileInfo.Title,
-				TextMatchTypeName,
-				rule.Triggers.UsernameTextMatch.Mode,
-				rule.Triggers.UsernameTextMatch.CaseSensitive,
-				attrs,
-				rule.Triggers.UsernameTextMatch.Patterns...))
+	newLists := slices.DeleteFunc(slices.Clone(e.rulesLists), func(existing *RuleSchema) bool {
+		return existing.sameList(list.BaseSchema)
+	})
+
+	e.rulesLists = append(newLists, list)
+
+	return count, errImport
+}
+
+// rebuildMatchers discards any registered matchers and registers new ones for each of the lists rules.
+func (rs *RuleSchema) rebuildMatchers() (int, error) {
+	var (
+		count     = 0
+		errImport error
+	)
+
+	rs.MatchersText = nil
+	rs.MatchersAvatar = nil
+	rs.MatchersRule = nil
+
+	for ruleIdx, rule := range rs.Rules {
+		registered, errRegister := rs.registerRule(rule)
+		if errRegister != nil {
+			errImport = errors.Join(errImport, fmt.Errorf("%w: list: %s rule: %d", errRegister, rs.FileInfo.Title, ruleIdx))
 
-			count++
+			continue
 		}
 
-		if rule.Triggers.ChatMsgTextMatch != nil {
-			attrs := rule.Triggers.ChatMsgTextMatch.Attributes
-			if len(attrs) == 0 {
-				attrs = append(attrs, "trigger_msg")
-			}
+		count += registered
+	}
+
+	return count, errImport
+}
+
+// registerRule creates and registers the matchers required for the rule, returning the number of matchers registered.
+func (rs *RuleSchema) registerRule(rule RuleDefinition) (int, error) {
+	nameMatcher, errName := newRuleNameMatcher(rs.FileInfo.Title, rule.Description, rule.Triggers)
+	if errName != nil {
+		return 0, errName
+	}
+
+	messageMatcher, errMessage := newRuleMessageMatcher(rs.FileInfo.Title, rule.Description, rule.Triggers)
+	if errMessage != nil {
+		return 0, errMessage
+	}
+
+	avatarMatchers, errAvatar := newRuleAvatarMatchers(rs.FileInfo.Title, rule.Description, rule.Triggers)
+	if errAvatar != nil {
+		return 0, errAvatar
+	}
+
+	// Matchers are wrapped so that their matches carry the actions, severity and weight of the rule
+	meta := newRuleMeta(rule)
+
+	if rule.Triggers.Mode == RuleTriggerModeMatchAll && rule.Triggers.triggerCount() > 1 {
+		ruleMatcher := NewRuleMatcher(rs.FileInfo.Title, rule.Description, RuleTriggerModeMatchAll, rule.Triggers.attributes())
+
+		if nameMatcher != nil {
+			ruleMatcher.RegisterNameMatcher(nameMatcher)
+		}
+
+		if messageMatcher != nil {
+			ruleMatcher.RegisterMessageMatcher(messageMatcher)
+		}
+
+		for _, avatarMatcher := range avatarMatchers {
+			ruleMatcher.RegisterAvatarMatcher(avatarMatcher)
+		}
+
+		rs.RegisterRuleMatcher(ruleGroupMatcher{matcher: ruleMatcher, meta: meta})
+
+		return 1, nil
+	}
+
+	count := 0
+
+	if nameMatcher != nil {
+		rs.RegisterTextMatcher(ruleTextMatcher{matcher: nameMatcher, meta: meta})
+
+		count++
+	}
+
+	if messageMatcher != nil {
+		rs.RegisterTextMatcher(ruleTextMatcher{matcher: messageMatcher, meta: meta})
+
+		count++
+	}
+
+	for _, avatarMatcher := range avatarMatchers {
+		rs.RegisterAvatarMatcher(ruleAvatarMatcher{matcher: avatarMatcher, meta: meta})
+
+		count++
+	}
+
+	return count, nil
+}
+
+// validateRule checks that the rule has at least one trigger and that all of its matchers can be created.
+func validateRule(rule RuleDefinition) error {
+	if rule.Triggers.triggerCount() == 0 {
+		return ErrInvalidRule
+	}
+
+	var scratch RuleSchema
+
+	if _, errRegister := scratch.registerRule(rule); errRegister != nil {
+		return errRegister
+	}
+
+	return nil
+}
+
+// Rules returns a copy of the rules defined in the local rules list.
+func (e *Engine) Rules() []RuleDefinition {
+	e.RLock()
+	defer e.RUnlock()
+
+	return slices.Clone(e.UserRuleList().Rules)
+}
+
+// AddRule appends a new rule to the local rules list, returning the index of the new rule.
+func (e *Engine) AddRule(rule RuleDefinition) (int, error) {
+	if errValidate := validateRule(rule); errValidate != nil {
+		return 0, errValidate
+	}
+
+	e.Lock()
+	defer e.Unlock()
+
+	list := e.UserRuleList()
+	list.Rules = append(list.Rules, rule)
+
+	if _, errRegister := list.registerRule(rule); errRegister != nil {
+		return 0, errRegister
+	}
+
+	return len(list.Rules) - 1, nil
+}
+
+// UpdateRule replaces the rule at the index of the local rules list.
+func (e *Engine) UpdateRule(index int, rule RuleDefinition) error {
+	if errValidate := validateRule(rule); errValidate != nil {
+		return errValidate
+	}
+
+	e.Lock()
+	defer e.Unlock()
+
+	list := e.UserRuleList()
+	if index < 0 || index >= len(list.Rules) {
+		return fmt.Errorf("%w: %d", ErrUnknownRule, index)
+	}
+
+	list.Rules[index] = rule
+
+	_, errRebuild := list.rebuildMatchers()
+
+	return errRebuild
+}
+
+// DeleteRule removes the rule at the index of the local rules list. The index of any following rules is shifted down.
+func (e *Engine) DeleteRule(index int) error {
+	e.Lock()
+	defer e.Unlock()
+
+	list := e.UserRuleList()
+	if index < 0 || index >= len(list.Rules) {
+		return fmt.Errorf("%w: %d", ErrUnknownRule, index)
+	}
+
+	list.Rules = slices.Delete(list.Rules, index, index+1)
+
+	_, errRebuild := list.rebuildMatchers()
+
+	return errRebuild
+}
 
-			list.RegisterTextMatcher(NewGeneralTextMatcher(
-				list.FileInfo.Title,
-				TextMatchTypeMessage,
-				rule.Triggers.ChatMsgTextMatch.Mode,
-				rule.Triggers.ChatMsgTextMatch.CaseSensitive,
-				attrs,
-				rule.Triggers.ChatMsgTextMatch.Patterns...))
+// RemoveList unloads all player and rules lists matching the title, update url or source url. The local lists
+// cannot be removed.
+func (e *Engine) RemoveList(titleOrURL string) error {
+	if titleOrURL == LocalRuleName {
+		return ErrRemoveLocalList
+	}
+
+	e.Lock()
+	defer e.Unlock()
+
+	var (
+		playerLists = make([]*PlayerListSchema, 0, len(e.playerLists))
+		rulesLists  = make([]*RuleSchema, 0, len(e.rulesLists))
+	)
 
-			count++
+	for _, list := range e.playerLists {
+		if list.isLocal() || !list.matches(titleOrURL) {
+			playerLists = append(playerLists, list)
 		}
+	}
 
-		if len(rule.Triggers.AvatarMatch) > 0 {
-			var hashes []string
+	for _, list := range e.rulesLists {
+		if list.isLocal() || !list.matches(titleOrURL) {
+			rulesLists = append(rulesLists, list)
+		}
+	}
+
+	if len(playerLists) == len(e.playerLists) && len(rulesLists) == len(e.rulesLists) {
+		return fmt.Errorf("%w: %s", ErrUnknownList, titleOrURL)
+	}
+
+	e.steamIndex.rebuild(playerLists)
+	e.playerLists = playerLists
+	e.rulesLists = rulesLists
 
-			for _, h := range rule.Triggers.AvatarMatch {
-				if len(h.AvatarHash) != 40 {
-					continue
-				}
+	return nil
+}
+
+func newRuleNameMatcher(origin string, description string, triggers RuleTriggers) (TextMatchHandler, error) {
+	if triggers.UsernameTextMatch == nil {
+		return nil, nil //nolint:nilnil
+	}
 
-				hashes = append(hashes, h.AvatarHash)
+	attrs := triggers.UsernameTextMatch.Attributes
+	if len(attrs) == 0 {
+		attrs = append(attrs, "trigger_name")
+	}
+
+	patterns := triggers.UsernameTextMatch.Patterns
+	if triggers.UsernameTextMatch.Normalize {
+		patterns = normalizePatterns(triggers.UsernameTextMatch.Mode, patterns)
+	}
+
+	matcher, errMatcher := NewGeneralTextMatcher(
+		origin,
+		description,
+		TextMatchTypeName,
+		triggers.UsernameTextMatch.Mode,
+		triggers.UsernameTextMatch.CaseSensitive,
+		attrs,
+		patterns...)
+	if errMatcher != nil {
+		return nil, errMatcher
+	}
+
+	matcher = matcher.WithThreshold(triggers.UsernameTextMatch.Threshold)
+
+	if triggers.UsernameTextMatch.Normalize {
+		return NewNormalizedTextMatcher(matcher), nil
+	}
+
+	return matcher, nil
+}
+
+func newRuleMessageMatcher(origin string, description string, triggers RuleTriggers) (TextMatchHandler, error) {
+	if triggers.ChatMsgTextMatch == nil {
+		return nil, nil //nolint:nilnil
+	}
+
+	attrs := triggers.ChatMsgTextMatch.Attributes
+	if len(attrs) == 0 {
+		attrs = append(attrs, "trigger_msg")
+	}
+
+	patterns := triggers.ChatMsgTextMatch.Patterns
+	if triggers.ChatMsgTextMatch.Normalize {
+		patterns = normalizePatterns(triggers.ChatMsgTextMatch.Mode, patterns)
+	}
+
+	matcher, errMatcher := NewGeneralTextMatcher(
+		origin,
+		description,
+		TextMatchTypeMessage,
+		triggers.ChatMsgTextMatch.Mode,
+		triggers.ChatMsgTextMatch.CaseSensitive,
+		attrs,
+		patterns...)
+	if errMatcher != nil {
+		return nil, errMatcher
+	}
+
+	matcher = matcher.WithThreshold(triggers.ChatMsgTextMatch.Threshold)
+
+	if triggers.ChatMsgTextMatch.Normalize {
+		return NewNormalizedTextMatcher(matcher), nil
+	}
+
+	return matcher, nil
+}
+
+// newRuleAvatarMatchers creates the matchers for the avatar triggers of the rule. Exact hashes are grouped into
+// a single matcher, while a perceptual matcher is created for each perceptual hash so that each can use its own
+// distance threshold.
+func newRuleAvatarMatchers(origin string, description string, triggers RuleTriggers) ([]AvatarMatcherHandler, error) {
+	if len(triggers.AvatarMatch) == 0 {
+		return nil, nil
+	}
+
+	var (
+		attrs    = []string{"trigger_avatar"}
+		exact    = false
+		hashes   []string
+		matchers []AvatarMatcherHandler
+	)
+
+	for _, trigger := range triggers.AvatarMatch {
+		if trigger.Mode == AvatarMatchPerceptual {
+			matcher, errMatcher := NewPerceptualAvatarMatcher(origin, description, attrs, trigger.Distance, trigger.AvatarHash)
+			if errMatcher != nil {
+				return nil, errMatcher
 			}
 
-			list.RegisterAvatarMatcher(NewAvatarMatcher(list.FileInfo.Title, AvatarMatchExact, hashes...))
+			matchers = append(matchers, matcher)
 
-			count++
+			continue
 		}
+
+		exact = true
+
+		if !ValidAvatarHash(trigger.AvatarHash) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidAvatarHash, trigger.AvatarHash)
+		}
+
+		hashes = append(hashes, trigger.AvatarHash)
 	}
 
-	e.rulesLists = append(e.rulesLists, list)
+	if exact {
+		matchers = append(matchers, NewAvatarMatcher(origin, description, AvatarMatchExact, attrs, hashes...))
+	}
 
-	return count, nil
+	return matchers, nil
 }
 
-// ImportPlayers loads the provided player list for matching.
+// ImportPlayers loads the provided player list for matching. Any existing list with the same source url is replaced,
+// as is the local list when importing the local list. Lists in either json or yaml can be decoded for import using
+// DecodeList.
 func (e *Engine) ImportPlayers(list *PlayerListSchema) (int, error) {
 	var (
 		playerAttrs []string
 		count       int
 	)
 
+	// Lists may be imported again after being updated, so any previously registered matchers are discarded
+	list.matchersSteam = nil
+
 	for _, player := range list.Players {
 		if !player.SteamID.Valid() {
 			return 0, errors.Join(steamid.ErrInvalidSID, ErrParseSteamID)
 		}
 
-		list.RegisterSteamIDMatcher(NewSteamIDMatcher(list.FileInfo.Title, player.SteamID, player.Attributes))
+		list.RegisterSteamIDMatcher(newPlayerSteamIDMatcher(list.FileInfo.Title, player))
 
 		playerAttrs = append(playerAttrs, player.Attributes...)
 		count++
@@ -359,13 +693,16 @@ func (e *Engine) ImportPlayers(list *PlayerListSchema) (int, error) {
 	var newLists []*PlayerListSchema
 
 	for _, lst := range e.playerLists {
-		if lst.FileInfo.Title != list.FileInfo.Title {
+		if !lst.sameList(list.BaseSchema) {
 			newLists = append(newLists, lst)
 		}
 	}
 
 	newLists = append(newLists, list)
 
+	// Any existing list with the same title or source is replaced, so the index must be rebuilt to drop its entries.
+	e.steamIndex.rebuild(newLists)
+
 	for _, newTag := range playerAttrs {
 		found := false
 
@@ -389,6 +726,41 @@ func (e *Engine) ImportPlayers(list *PlayerListSchema) (int, error) {
 
 func (pls *PlayerListSchema) RegisterSteamIDMatcher(matcher SteamIDMatcherHandler) {
 	pls.matchersSteam = append(pls.matchersSteam, matcher)
+
+	if pls.steamIndex == nil {
+		pls.steamIndex = map[steamid.SteamID][]SteamIDMatcherHandler{}
+	}
+
+	sid64 := matcher.SteamID()
+	pls.steamIndex[sid64] = append(pls.steamIndex[sid64], matcher)
+
+	if pls.aggregate != nil {
+		pls.aggregate.add(sid64, pls)
+	}
+}
+
+// unregisterSteamID removes all matchers for the steam id from the list.
+func (pls *PlayerListSchema) unregisterSteamID(sid64 steamid.SteamID) {
+	pls.matchersSteam = slices.DeleteFunc(pls.matchersSteam, func(matcher SteamIDMatcherHandler) bool {
+		return matcher.SteamID() == sid64
+	})
+
+	delete(pls.steamIndex, sid64)
+
+	if pls.aggregate != nil {
+		pls.aggregate.remove(sid64, pls)
+	}
+}
+
+// matchSteam checks the list for any matchers of the steam id.
+func (pls *PlayerListSchema) matchSteam(sid64 steamid.SteamID) (MatchResult, bool) {
+	for _, matcher := range pls.steamIndex[sid64] {
+		if match, found := matcher.Match(sid64); found {
+			return match, true
+		}
+	}
+
+	return MatchResult{}, false
 }
 
 func (rs *RuleSchema) RegisterAvatarMatcher(matcher AvatarMatcherHandler) {
@@ -399,6 +771,10 @@ func (rs *RuleSchema) RegisterTextMatcher(matcher TextMatchHandler) {
 	rs.MatchersText = append(rs.MatchersText, matcher)
 }
 
+func (rs *RuleSchema) RegisterRuleMatcher(matcher RuleMatchHandler) {
+	rs.MatchersRule = append(rs.MatchersRule, matcher)
+}
+
 func (rs *RuleSchema) matchTextType(text string, matchType TextMatchType) (MatchResult, bool) {
 	for _, matcher := range rs.MatchersText {
 		if matcher.Type() != TextMatchTypeAny && matcher.Type() != matchType {
@@ -406,7 +782,7 @@ func (rs *RuleSchema) matchTextType(text string, matchType TextMatchType) (Match
 		}
 
 		if match, found := matcher.Match(text); found {
-			return match, true
+			return rs.withSeverity(match, SeverityMedium), true
 		}
 	}
 
@@ -419,13 +795,11 @@ func (e *Engine) MatchSteam(steamID steamid.SteamID) MatchResults {
 
 	var matches MatchResults
 
-	for _, list := range e.playerLists {
-		for _, sm := range list.matchersSteam {
-			if match, found := sm.Match(steamID); found {
-				matches = append(matches, match)
-
-				break
-			}
+	for _, list := range e.steamIndex.get(steamID) {
+		if match, found := list.matchSteam(steamID); found {
+			match = e.taxonomy.canonicalResult(match)
+			match = list.withSeverity(match, e.taxonomy.Severity(match.Attributes))
+			matches = append(matches, match)
 		}
 	}
 
@@ -433,6 +807,9 @@ func (e *Engine) MatchSteam(steamID steamid.SteamID) MatchResults {
 }
 
 func (e *Engine) MatchName(name string) []MatchResult {
+	e.RLock()
+	defer e.RUnlock()
+
 	var results MatchResults
 
 	for _, list := range e.rulesLists {
@@ -443,10 +820,13 @@ func (e *Engine) MatchName(name string) []MatchResult {
 		}
 	}
 
-	return results
+	return e.canonicalResults(results)
 }
 
 func (e *Engine) MatchMessage(text string) []MatchResult {
+	e.RLock()
+	defer e.RUnlock()
+
 	var results MatchResults
 
 	for _, list := range e.rulesLists {
@@ -457,9 +837,72 @@ func (e *Engine) MatchMessage(text string) []MatchResult {
 		}
 	}
 
+	return e.canonicalResults(results)
+}
+
+// MatchPlayer evaluates all the loaded rules against the players data. Unlike the MatchName and MatchMessage
+// functions, this includes rules that require multiple triggers to match at once.
+func (e *Engine) MatchPlayer(input PlayerInput) []MatchResult {
+	e.RLock()
+	defer e.RUnlock()
+
+	var results MatchResults
+
+	for _, list := range e.rulesLists {
+		results = append(results, list.matchPlayer(input)...)
+	}
+
+	return e.canonicalResults(results)
+}
+
+// matchPlayer evaluates all the lists matchers against the players data.
+func (rs *RuleSchema) matchPlayer(input PlayerInput) []MatchResult {
+	var results MatchResults
+
+	if input.Name != "" {
+		if match, found := rs.matchTextType(input.Name, TextMatchTypeName); found {
+			results = append(results, match)
+		}
+	}
+
+	for _, message := range input.Messages {
+		if match, found := rs.matchTextType(message, TextMatchTypeMessage); found {
+			results = append(results, match)
+
+			break
+		}
+	}
+
+	if match, found := rs.matchAvatarHash(input.AvatarHash, input.AvatarPerceptualHash); found {
+		results = append(results, match)
+	}
+
+	for _, matcher := range rs.MatchersRule {
+		if match, found := matcher.Match(input); found {
+			results = append(results, rs.withSeverity(match, SeverityMedium))
+		}
+	}
+
 	return results
 }
 
+// matchAvatarHash checks the hashes against all the lists avatar matchers. Each matcher is given the hash
+// in the format used by its type.
+func (rs *RuleSchema) matchAvatarHash(hash string, perceptualHash string) (MatchResult, bool) {
+	for _, matcher := range rs.MatchersAvatar {
+		hexDigest := avatarDigest(matcher.Type(), hash, perceptualHash)
+		if hexDigest == "" {
+			continue
+		}
+
+		if match, found := matcher.Match(hexDigest); found {
+			return rs.withSeverity(match, SeverityMedium), true
+		}
+	}
+
+	return MatchResult{}, false
+}
+
 // func (e *Engine) matchAny(text string) *MatchResult {
 //	   return e.matchTextType(text, TextMatchTypeAny)
 // }
@@ -469,22 +912,27 @@ func (e *Engine) MatchAvatar(avatar []byte) []MatchResult {
 		return nil
 	}
 
+	e.RLock()
+	defer e.RUnlock()
+
 	var (
-		hexDigest = HashBytes(avatar)
+		hexDigest = HashAvatar(avatar)
 		matches   []MatchResult
 	)
 
-	for _, list := range e.rulesLists {
-		for _, matcher := range list.MatchersAvatar {
-			if match, found := matcher.Match(hexDigest); found {
-				matches = append(matches, match)
+	// Images that cannot be decoded can still be matched by their exact hash
+	perceptualHash, errPerceptual := PerceptualHash(avatar)
+	if errPerceptual != nil {
+		slog.Debug("Failed to compute perceptual avatar hash", slog.String("error", errPerceptual.Error()))
+	}
 
-				break
-			}
+	for _, list := range e.rulesLists {
+		if match, found := list.matchAvatarHash(hexDigest, perceptualHash); found {
+			matches = append(matches, match)
 		}
 	}
 
-	return matches
+	return e.canonicalResults(matches)
 }
 
 const (
@@ -516,6 +964,26 @@ func (e *Engine) ExportVoiceBans(tf2Dir string, kickTags []string) error {
 	return nil
 }
 
+// ValidAvatarHash returns true if the hash is a hex encoded sha1 hash, as created by HashAvatar.
+func ValidAvatarHash(hash string) bool {
+	if len(hash) != sha1.Size*2 {
+		return false
+	}
+
+	_, errDecode := hex.DecodeString(hash)
+
+	return errDecode == nil
+}
+
+// HashAvatar returns the hex encoded sha1 digest of the avatar image. This matches the format of the avatar hashes
+// used by steam and TF2BD rules, so the hash of a downloaded avatar image can be compared directly against them.
+func HashAvatar(avatar []byte) string {
+	hash := sha1.New() //nolint:gosec
+	hash.Write(avatar)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
 func HashBytes(b []byte) string {
 	hash := sha256.New()
 	hash.Write(b)
@@ -0,0 +1,93 @@
+package rules
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/leighmacdonald/steamid/v4/steamid"
+)
+
+const (
+	benchListCount   = 3
+	benchListEntries = 20000
+	benchBaseSteamID = 76561197960265729
+)
+
+func benchEngine(b *testing.B) *Engine {
+	b.Helper()
+
+	engine := New()
+
+	for listIdx := 0; listIdx < benchListCount; listIdx++ {
+		list := PlayerListSchema{BaseSchema: BaseSchema{FileInfo: FileInfo{Title: fmt.Sprintf("bench %d", listIdx)}}}
+
+		for entry := 0; entry < benchListEntries; entry++ {
+			list.Players = append(list.Players, PlayerDefinition{
+				SteamID:    steamid.New(int64(benchBaseSteamID + (listIdx * benchListEntries) + entry)),
+				Attributes: []string{"cheater"},
+			})
+		}
+
+		if _, errImport := engine.ImportPlayers(&list); errImport != nil {
+			b.Fatal(errImport)
+		}
+	}
+
+	return engine
+}
+
+// matchSteamLinear is the previous implementation of MatchSteam, kept as a baseline for comparison.
+func (e *Engine) matchSteamLinear(steamID steamid.SteamID) MatchResults {
+	e.RLock()
+	defer e.RUnlock()
+
+	var matches MatchResults
+
+	for _, list := range e.playerLists {
+		for _, sm := range list.matchersSteam {
+			if match, found := sm.Match(steamID); found {
+				matches = append(matches, match)
+
+				break
+			}
+		}
+	}
+
+	return matches
+}
+
+func benchSteamIDs() steamid.Collection {
+	// A mix of listed players and players not on any list, like a typical server.
+	return steamid.Collection{
+		steamid.New(int64(benchBaseSteamID + 10)),
+		steamid.New(int64(benchBaseSteamID + benchListEntries + 500)),
+		steamid.New(int64(benchBaseSteamID + (benchListCount * benchListEntries) - 1)),
+		steamid.New(int64(benchBaseSteamID + (benchListCount * benchListEntries) + 1000)),
+	}
+}
+
+func BenchmarkMatchSteam(b *testing.B) {
+	engine := benchEngine(b)
+	steamIDs := benchSteamIDs()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, sid := range steamIDs {
+			engine.MatchSteam(sid)
+		}
+	}
+}
+
+func BenchmarkMatchSteamLinear(b *testing.B) {
+	engine := benchEngine(b)
+	steamIDs := benchSteamIDs()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, sid := range steamIDs {
+			engine.matchSteamLinear(sid)
+		}
+	}
+}
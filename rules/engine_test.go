@@ -105,6 +105,52 @@ func TestSteamRules(t *testing.T) {
 	require.Nil(t, engine.MatchSteam(steamid.New(testSteamID.Int64()+1)), "Matched invalid steamid")
 }
 
+func TestSteamIndex(t *testing.T) {
+	var (
+		engine    = rules.New()
+		markedID  = steamid.New(76561197961279983)
+		listedID  = steamid.New(76561197970669109)
+		otherList = rules.PlayerListSchema{
+			BaseSchema: rules.BaseSchema{FileInfo: rules.FileInfo{Title: customListTitle}, SourceURL: "http://localhost/players.json"},
+			Players: []rules.PlayerDefinition{
+				{SteamID: markedID, Attributes: []string{"cheater"}},
+				{SteamID: listedID, Attributes: []string{"bot"}},
+			},
+		}
+	)
+
+	_, errImport := engine.ImportPlayers(&otherList)
+	require.NoError(t, errImport)
+	require.NoError(t, engine.Mark(rules.MarkOpts{SteamID: markedID, Attributes: []string{"racist"}}))
+	require.Len(t, engine.MatchSteam(markedID), 2)
+	require.Len(t, engine.MatchSteam(listedID), 1)
+
+	// Adding attributes should update the existing local entry
+	require.NoError(t, engine.Mark(rules.MarkOpts{SteamID: markedID, Attributes: []string{"cheater"}}))
+
+	for _, match := range engine.MatchSteam(markedID) {
+		if match.Origin == rules.LocalRuleName {
+			require.True(t, match.HasAttr("racist"))
+			require.True(t, match.HasAttr("cheater"))
+		}
+	}
+
+	require.True(t, engine.Unmark(markedID))
+	require.Len(t, engine.MatchSteam(markedID), 1)
+	require.Equal(t, customListTitle, engine.MatchSteam(markedID)[0].Origin)
+
+	// Re-importing a list from the same source replaces the previous entries
+	replacement := rules.PlayerListSchema{
+		BaseSchema: rules.BaseSchema{FileInfo: rules.FileInfo{Title: customListTitle}, SourceURL: otherList.SourceURL},
+		Players:    []rules.PlayerDefinition{{SteamID: listedID, Attributes: []string{"bot"}}},
+	}
+
+	_, errReplace := engine.ImportPlayers(&replacement)
+	require.NoError(t, errReplace)
+	require.Nil(t, engine.MatchSteam(markedID))
+	require.Len(t, engine.MatchSteam(listedID), 1)
+}
+
 func TestTextRules(t *testing.T) {
 	engine := rules.New()
 	tr := genTestRules()
@@ -114,13 +160,15 @@ func TestTextRules(t *testing.T) {
 	testAttrs := []string{"test_attr"}
 
 	list := engine.UserRuleList()
-	list.RegisterTextMatcher(rules.NewGeneralTextMatcher(customListTitle, rules.TextMatchTypeName, rules.TextMatchModeContains, false, testAttrs, "test", "blah"))
+	gm, eGm := rules.NewGeneralTextMatcher(customListTitle, "", rules.TextMatchTypeName, rules.TextMatchModeContains, false, testAttrs, "test", "blah")
+	require.NoError(t, eGm)
+	list.RegisterTextMatcher(gm)
 
-	rm, eRm := rules.NewRegexTextMatcher(customListTitle, rules.TextMatchTypeMessage, testAttrs, `^test.+?`)
+	rm, eRm := rules.NewRegexTextMatcher(customListTitle, "", rules.TextMatchTypeMessage, testAttrs, `^test.+?`)
 	require.NoError(t, eRm)
 	list.RegisterTextMatcher(rm)
 
-	_, badRegex := rules.NewRegexTextMatcher(customListTitle, rules.TextMatchTypeName, testAttrs, `^t\s\x\t`)
+	_, badRegex := rules.NewRegexTextMatcher(customListTitle, "", rules.TextMatchTypeName, testAttrs, `^t\s\x\t`)
 	require.Error(t, badRegex)
 
 	testCases := []struct {
@@ -153,6 +201,53 @@ func TestTextRules(t *testing.T) {
 	require.Error(t, engine.Mark(rules.MarkOpts{}))
 }
 
+func TestRegexRules(t *testing.T) {
+	engine := rules.New()
+	list := rules.RuleSchema{
+		BaseSchema: rules.BaseSchema{FileInfo: rules.FileInfo{Title: customListTitle}},
+		Rules: []rules.RuleDefinition{
+			{
+				Description: "regex ci",
+				Triggers: rules.RuleTriggers{
+					UsernameTextMatch: &rules.RuleTriggerNameMatch{
+						Mode:     rules.TextMatchModeRegex,
+						Patterns: []string{`^bot_\d{3}$`},
+					},
+				},
+			},
+			{
+				Description: "invalid regex",
+				Triggers: rules.RuleTriggers{
+					UsernameTextMatch: &rules.RuleTriggerNameMatch{
+						Mode:     rules.TextMatchModeRegex,
+						Patterns: []string{`^t\s\x\t`},
+					},
+				},
+			},
+			{
+				Description: "regex cs",
+				Triggers: rules.RuleTriggers{
+					ChatMsgTextMatch: &rules.RuleTriggerTextMatch{
+						CaseSensitive: true,
+						Mode:          rules.TextMatchModeRegex,
+						Patterns:      []string{`FREE\s+(KEYS|ITEMS)`},
+					},
+				},
+			},
+		},
+	}
+
+	count, errImport := engine.ImportRules(&list)
+	require.ErrorIs(t, errImport, rules.ErrInvalidRegex)
+	require.ErrorContains(t, errImport, "list: Custom List rule: 1")
+	require.Equal(t, 2, count)
+
+	require.NotNil(t, engine.MatchName("BOT_123"))
+	require.Nil(t, engine.MatchName("bot_1234"))
+	require.NotNil(t, engine.MatchMessage("get FREE  KEYS now"))
+	require.Nil(t, engine.MatchMessage("get free keys now"))
+}
+
 func TestAvatarRules(t *testing.T) {
 	const listName = "test avatar"
 
@@ -165,9 +260,400 @@ func TestAvatarRules(t *testing.T) {
 	require.NoError(t, jpeg.Encode(bufio.NewWriter(&buf), testAvatar, &jpeg.Options{Quality: 10}))
 
 	list := engine.UserRuleList()
-	list.RegisterAvatarMatcher(rules.NewAvatarMatcher(listName, rules.AvatarMatchExact, rules.HashBytes(buf.Bytes())))
+	list.RegisterAvatarMatcher(rules.NewAvatarMatcher(listName, "", rules.AvatarMatchExact, []string{"bot"}, rules.HashAvatar(buf.Bytes())))
 
 	result := engine.MatchAvatar(buf.Bytes())
 	require.NotNil(t, result)
 	require.Equal(t, listName, result[0].Origin)
+	require.Equal(t, rules.HashAvatar(buf.Bytes()), result[0].Pattern)
+	require.True(t, result[0].HasAttr("bot"))
+}
+
+func TestTextMatchResults(t *testing.T) {
+	const description = "test rule"
+
+	testAttrs := []string{"test_attr"}
+
+	testCases := []struct {
+		mode          rules.TextMatchMode
+		caseSensitive bool
+		pattern       string
+		text          string
+		matched       bool
+	}{
+		{mode: rules.TextMatchModeContains, caseSensitive: true, pattern: "Bot", text: "a Bot name", matched: true},
+		{mode: rules.TextMatchModeContains, caseSensitive: true, pattern: "Bot", text: "a bot name", matched: false},
+		{mode: rules.TextMatchModeContains, caseSensitive: false, pattern: "Bot", text: "a bOT name", matched: true},
+		{mode: rules.TextMatchModeContains, caseSensitive: false, pattern: "Bot", text: "a human name", matched: false},
+		{mode: rules.TextMatchModeStartsWith, caseSensitive: true, pattern: "Bot", text: "Bot name", matched: true},
+		{mode: rules.TextMatchModeStartsWith, caseSensitive: true, pattern: "Bot", text: "bot name", matched: false},
+		{mode: rules.TextMatchModeStartsWith, caseSensitive: false, pattern: "Bot", text: "BOT name", matched: true},
+		{mode: rules.TextMatchModeStartsWith, caseSensitive: false, pattern: "Bot", text: "name bot", matched: false},
+		{mode: rules.TextMatchModeEndsWith, caseSensitive: true, pattern: "Bot", text: "name Bot", matched: true},
+		{mode: rules.TextMatchModeEndsWith, caseSensitive: true, pattern: "Bot", text: "name bot", matched: false},
+		{mode: rules.TextMatchModeEndsWith, caseSensitive: false, pattern: "Bot", text: "name BOT", matched: true},
+		{mode: rules.TextMatchModeEndsWith, caseSensitive: false, pattern: "Bot", text: "bot name", matched: false},
+		{mode: rules.TextMatchModeEqual, caseSensitive: true, pattern: "Bot", text: "Bot", matched: true},
+		{mode: rules.TextMatchModeEqual, caseSensitive: true, pattern: "Bot", text: "bot", matched: false},
+		{mode: rules.TextMatchModeEqual, caseSensitive: false, pattern: "Bot", text: "bOt", matched: true},
+		{mode: rules.TextMatchModeEqual, caseSensitive: false, pattern: "Bot", text: "bots", matched: false},
+		{mode: rules.TextMatchModeWord, caseSensitive: true, pattern: "Bot", text: "a Bot name", matched: true},
+		{mode: rules.TextMatchModeWord, caseSensitive: true, pattern: "Bot", text: "a bot name", matched: false},
+		{mode: rules.TextMatchModeWord, caseSensitive: false, pattern: "Bot", text: "a BOT name", matched: true},
+		{mode: rules.TextMatchModeWord, caseSensitive: false, pattern: "Bot", text: "a bots name", matched: false},
+		{mode: rules.TextMatchModeRegex, caseSensitive: true, pattern: `^Bot\d+$`, text: "Bot123", matched: true},
+		{mode: rules.TextMatchModeRegex, caseSensitive: true, pattern: `^Bot\d+$`, text: "bot123", matched: false},
+		{mode: rules.TextMatchModeRegex, caseSensitive: false, pattern: `^Bot\d+$`, text: "BOT123", matched: true},
+		{mode: rules.TextMatchModeRegex, caseSensitive: false, pattern: `^Bot\d+$`, text: "bot", matched: false},
+	}
+
+	for num, testCase := range testCases {
+		matcher, errMatcher := rules.NewGeneralTextMatcher(customListTitle, description, rules.TextMatchTypeName,
+			testCase.mode, testCase.caseSensitive, testAttrs, "unrelated", testCase.pattern)
+		require.NoError(t, errMatcher)
+
+		result, matched := matcher.Match(testCase.text)
+		require.Equal(t, testCase.matched, matched, "Test %d failed", num)
+
+		if !testCase.matched {
+			continue
+		}
+
+		require.Equal(t, rules.MatchResult{
+			Origin:      customListTitle,
+			Attributes:  testAttrs,
+			MatcherType: string(rules.TextMatchTypeName),
+			Pattern:     testCase.pattern,
+			Description: description,
+		}, result, "Test %d failed", num)
+	}
+}
+
+func TestMultiTriggerRules(t *testing.T) {
+	const avatarHash = "0123456789abcdef0123456789abcdef01234567"
+
+	engine := rules.New()
+	list := rules.RuleSchema{
+		BaseSchema: rules.BaseSchema{FileInfo: rules.FileInfo{Title: customListTitle}},
+		Rules: []rules.RuleDefinition{
+			{
+				Description: "name, message and avatar",
+				Triggers: rules.RuleTriggers{
+					Mode:        rules.RuleTriggerModeMatchAll,
+					AvatarMatch: []rules.RuleTriggerAvatarMatch{{AvatarHash: avatarHash}},
+					UsernameTextMatch: &rules.RuleTriggerNameMatch{
+						Mode:       rules.TextMatchModeContains,
+						Patterns:   []string{"multi_bot"},
+						Attributes: []string{"bot"},
+					},
+					ChatMsgTextMatch: &rules.RuleTriggerTextMatch{
+						Mode:     rules.TextMatchModeStartsWith,
+						Patterns: []string{"join our discord"},
+					},
+				},
+			},
+		},
+	}
+
+	count, errImport := engine.ImportRules(&list)
+	require.NoError(t, errImport)
+	require.Equal(t, 1, count)
+
+	testCases := []struct {
+		input   rules.PlayerInput
+		matched bool
+	}{
+		{input: rules.PlayerInput{Name: "multi_bot 01", Messages: []string{"gg", "join our discord"}, AvatarHash: avatarHash}, matched: true},
+		{input: rules.PlayerInput{Name: "multi_bot 01", Messages: []string{"gg"}, AvatarHash: avatarHash}, matched: false},
+		{input: rules.PlayerInput{Name: "multi_bot 01", Messages: []string{"join our discord"}}, matched: false},
+		{input: rules.PlayerInput{Name: "Uncle Dane", Messages: []string{"join our discord"}, AvatarHash: avatarHash}, matched: false},
+	}
+
+	for num, testCase := range testCases {
+		results := engine.MatchPlayer(testCase.input)
+		require.Equal(t, testCase.matched, results != nil, "Test %d failed", num)
+
+		if testCase.matched {
+			require.True(t, results[0].HasAttr("bot"))
+		}
+	}
+
+	// Individual triggers of a match_all rule must not match on their own
+	require.Nil(t, engine.MatchName("multi_bot 01"))
+	require.Nil(t, engine.MatchMessage("join our discord"))
+
+	// Rules with a malformed exact hash could never match, so they are skipped
+	invalid := list
+	invalid.FileInfo.Title = "invalid avatar"
+	invalid.Rules = []rules.RuleDefinition{{
+		Description: "malformed avatar",
+		Triggers: rules.RuleTriggers{
+			Mode:              rules.RuleTriggerModeMatchAll,
+			AvatarMatch:       []rules.RuleTriggerAvatarMatch{{AvatarHash: "not a hash"}},
+			UsernameTextMatch: &rules.RuleTriggerNameMatch{Mode: rules.TextMatchModeContains, Patterns: []string{"bot"}},
+		},
+	}}
+
+	invalidCount, errInvalid := engine.ImportRules(&invalid)
+	require.ErrorIs(t, errInvalid, rules.ErrInvalidAvatarHash)
+	require.Zero(t, invalidCount)
+}
+
+func TestSimilarRules(t *testing.T) {
+	engine := rules.New()
+	list := rules.RuleSchema{
+		BaseSchema: rules.BaseSchema{FileInfo: rules.FileInfo{Title: customListTitle}},
+		Rules: []rules.RuleDefinition{
+			{
+				Description: "similar default threshold",
+				Triggers: rules.RuleTriggers{
+					UsernameTextMatch: &rules.RuleTriggerNameMatch{
+						Mode:     rules.TextMatchModeSimilar,
+						Patterns: []string{"OMEGATRONIC"},
+					},
+				},
+			},
+			{
+				Description: "similar custom threshold",
+				Triggers: rules.RuleTriggers{
+					UsernameTextMatch: &rules.RuleTriggerNameMatch{
+						CaseSensitive: true,
+						Mode:          rules.TextMatchModeSimilar,
+						Patterns:      []string{"swordstoned"},
+						Threshold:     0.6,
+					},
+				},
+			},
+		},
+	}
+
+	_, errImport := engine.ImportRules(&list)
+	require.NoError(t, errImport)
+
+	testCases := []struct {
+		name       string
+		matched    bool
+		similarity float64
+	}{
+		{name: "omegatronic", matched: true, similarity: 1},
+		{name: "OMEGATR0NIC", matched: true, similarity: 1 - 1.0/11},
+		{name: "OMEGATR0NIC1", matched: true, similarity: 1 - 2.0/12},
+		{name: "0MEGATR0NIC1", matched: false},
+		{name: "0MEGA_TR0N1C_", matched: false},
+		{name: "sw0rdst0n3d", matched: true, similarity: 1 - 3.0/11},
+		{name: "SWORDSTONED", matched: false},
+		{name: "Uncle Dane", matched: false},
+	}
+
+	for num, testCase := range testCases {
+		results := engine.MatchName(testCase.name)
+		require.Equal(t, testCase.matched, results != nil, "Test %d failed", num)
+
+		if testCase.matched {
+			require.InDelta(t, testCase.similarity, results[0].Similarity, 0.0001, "Test %d failed", num)
+		}
+	}
+}
+
+func TestRuleCRUD(t *testing.T) {
+	engine := rules.New()
+	nameRule := func(pattern string) rules.RuleDefinition {
+		return rules.RuleDefinition{
+			Description: pattern,
+			Triggers: rules.RuleTriggers{
+				UsernameTextMatch: &rules.RuleTriggerNameMatch{Mode: rules.TextMatchModeEqual, Patterns: []string{pattern}},
+			},
+		}
+	}
+
+	_, errEmpty := engine.AddRule(rules.RuleDefinition{Description: "empty"})
+	require.ErrorIs(t, errEmpty, rules.ErrInvalidRule)
+
+	_, errRegex := engine.AddRule(rules.RuleDefinition{
+		Triggers: rules.RuleTriggers{
+			UsernameTextMatch: &rules.RuleTriggerNameMatch{Mode: rules.TextMatchModeRegex, Patterns: []string{`^t\s\x\t`}},
+		},
+	})
+	require.ErrorIs(t, errRegex, rules.ErrInvalidRegex)
+	require.Empty(t, engine.Rules())
+
+	for idx, name := range []string{"first", "second", "third"} {
+		ruleIdx, errAdd := engine.AddRule(nameRule(name))
+		require.NoError(t, errAdd)
+		require.Equal(t, idx, ruleIdx)
+	}
+
+	require.Len(t, engine.Rules(), 3)
+	require.NotNil(t, engine.MatchName("second"))
+
+	require.NoError(t, engine.UpdateRule(1, nameRule("updated")))
+	require.Nil(t, engine.MatchName("second"))
+	require.NotNil(t, engine.MatchName("updated"))
+	require.ErrorIs(t, engine.UpdateRule(1, rules.RuleDefinition{}), rules.ErrInvalidRule)
+	require.NotNil(t, engine.MatchName("updated"), "Invalid updates should not modify the rule")
+	require.ErrorIs(t, engine.UpdateRule(3, nameRule("missing")), rules.ErrUnknownRule)
+
+	require.NoError(t, engine.DeleteRule(0))
+	require.Nil(t, engine.MatchName("first"))
+	require.Equal(t, "updated", engine.Rules()[0].Description)
+	require.Equal(t, "third", engine.Rules()[1].Description)
+	require.ErrorIs(t, engine.DeleteRule(2), rules.ErrUnknownRule)
+	require.ErrorIs(t, engine.DeleteRule(-1), rules.ErrUnknownRule)
+}
+
+func TestListLifecycle(t *testing.T) {
+	const sourceURL = "http://localhost/rules.json"
+
+	var (
+		engine    = rules.New()
+		playerID  = steamid.New(76561197961279983)
+		ruleList  = genTestRules()
+		ruleTitle = ruleList.FileInfo.Title
+	)
+
+	ruleList.SourceURL = sourceURL
+
+	_, errImport := engine.ImportRules(&ruleList)
+	require.NoError(t, errImport)
+	require.NotNil(t, engine.MatchName("name_regex_test"))
+
+	// Importing a list from the same source replaces it instead of matching twice
+	replacement := genTestRules()
+	replacement.SourceURL = sourceURL
+	_, errReplace := engine.ImportRules(&replacement)
+	require.NoError(t, errReplace)
+	require.Len(t, engine.MatchName("name_regex_test"), 1)
+
+	playerList := rules.PlayerListSchema{
+		BaseSchema: rules.BaseSchema{FileInfo: rules.FileInfo{Title: customListTitle, UpdateURL: "http://localhost/players.json"}},
+		Players:    []rules.PlayerDefinition{{SteamID: playerID, Attributes: []string{"cheater"}}},
+	}
+
+	_, errPlayers := engine.ImportPlayers(&playerList)
+	require.NoError(t, errPlayers)
+	require.Len(t, engine.MatchSteam(playerID), 1)
+
+	require.NoError(t, engine.RemoveList("http://localhost/players.json"))
+	require.Empty(t, engine.MatchSteam(playerID))
+	require.NotNil(t, engine.MatchName("name_regex_test"))
+
+	require.NoError(t, engine.RemoveList(ruleTitle))
+	require.Nil(t, engine.MatchName("name_regex_test"))
+
+	require.ErrorIs(t, engine.RemoveList(ruleTitle), rules.ErrUnknownList)
+	require.ErrorIs(t, engine.RemoveList(rules.LocalRuleName), rules.ErrRemoveLocalList)
+	require.NotNil(t, engine.UserRuleList())
+	require.NotNil(t, engine.UserPlayerList())
+}
+
+func TestListIdentity(t *testing.T) {
+	var (
+		engine   = rules.New()
+		firstID  = steamid.New(76561197961279983)
+		secondID = steamid.New(76561197970669109)
+		markedID = steamid.New(76561197992870439)
+		newList  = func(sourceURL string, title string, steamID steamid.SteamID) *rules.PlayerListSchema {
+			return &rules.PlayerListSchema{
+				BaseSchema: rules.BaseSchema{FileInfo: rules.FileInfo{Title: title}, SourceURL: sourceURL},
+				Players:    []rules.PlayerDefinition{{SteamID: steamID, Attributes: []string{"cheater"}}},
+			}
+		}
+	)
+
+	// Unrelated lists which share a title are both loaded
+	first := newList("http://localhost/first.json", "Shared", firstID)
+	_, errFirst := engine.ImportPlayers(first)
+	require.NoError(t, errFirst)
+
+	_, errSecond := engine.ImportPlayers(newList("http://localhost/second.json", "Shared", secondID))
+	require.NoError(t, errSecond)
+	require.Len(t, engine.MatchSteam(firstID), 1)
+	require.Len(t, engine.MatchSteam(secondID), 1)
+
+	// Importing the same list again does not register its entries twice
+	_, errReimport := engine.ImportPlayers(first)
+	require.NoError(t, errReimport)
+	require.Len(t, engine.MatchSteam(firstID), 1)
+
+	// A remote list titled local never replaces the users local list
+	require.NoError(t, engine.Mark(rules.MarkOpts{SteamID: markedID, Attributes: []string{"bot"}}))
+
+	remoteLocal := newList("http://localhost/local.json", rules.LocalRuleName, secondID)
+	_, errRemote := engine.ImportPlayers(remoteLocal)
+	require.NoError(t, errRemote)
+	require.Len(t, engine.MatchSteam(markedID), 1)
+	require.NotSame(t, remoteLocal, engine.UserPlayerList())
+
+	require.NoError(t, engine.Mark(rules.MarkOpts{SteamID: firstID, Attributes: []string{"bot"}}))
+	require.Len(t, remoteLocal.Players, 1, "Marks should not be written to the remote list")
+
+	remoteRules := genTestRules()
+	remoteRules.FileInfo.Title = rules.LocalRuleName
+	remoteRules.SourceURL = "http://localhost/local_rules.json"
+
+	_, errRules := engine.ImportRules(&remoteRules)
+	require.NoError(t, errRules)
+	require.NotSame(t, &remoteRules, engine.UserRuleList())
+	require.NotNil(t, engine.MatchName("name_regex_test"))
+}
+
+func TestRuleActions(t *testing.T) {
+	engine := rules.New()
+	list := rules.RuleSchema{
+		BaseSchema: rules.BaseSchema{FileInfo: rules.FileInfo{Title: customListTitle}},
+		Rules: []rules.RuleDefinition{
+			{
+				Description: "marked",
+				Actions:     rules.RuleActions{Mark: []string{"cheater"}},
+				Triggers: rules.RuleTriggers{
+					UsernameTextMatch: &rules.RuleTriggerNameMatch{Mode: rules.TextMatchModeEqual, Patterns: []string{"cheater"}},
+				},
+			},
+			{
+				Description: "transient",
+				Actions:     rules.RuleActions{TransientMark: []string{"suspicious"}},
+				Triggers: rules.RuleTriggers{
+					ChatMsgTextMatch: &rules.RuleTriggerTextMatch{Mode: rules.TextMatchModeContains, Patterns: []string{"discord.gg"}},
+				},
+			},
+			{
+				Description: "both",
+				Actions:     rules.RuleActions{Mark: []string{"racist"}, TransientMark: []string{"suspicious"}},
+				Triggers: rules.RuleTriggers{
+					Mode:              rules.RuleTriggerModeMatchAll,
+					UsernameTextMatch: &rules.RuleTriggerNameMatch{Mode: rules.TextMatchModeEqual, Patterns: []string{"bigot"}},
+					ChatMsgTextMatch:  &rules.RuleTriggerTextMatch{Mode: rules.TextMatchModeContains, Patterns: []string{"slur"}},
+				},
+			},
+			{
+				Description: "none",
+				Triggers: rules.RuleTriggers{
+					UsernameTextMatch: &rules.RuleTriggerNameMatch{Mode: rules.TextMatchModeEqual, Patterns: []string{"plain"}},
+				},
+			},
+		},
+	}
+
+	_, errImport := engine.ImportRules(&list)
+	require.NoError(t, errImport)
+
+	marked := engine.MatchName("cheater")
+	require.Len(t, marked, 1)
+	require.Equal(t, []string{"cheater"}, marked[0].Mark)
+	require.Empty(t, marked[0].TransientMark)
+
+	transient := engine.MatchMessage("join discord.gg")
+	require.Len(t, transient, 1)
+	require.Equal(t, []string{"suspicious"}, transient[0].TransientMark)
+	require.Empty(t, transient[0].Mark)
+
+	both := engine.MatchPlayer(rules.PlayerInput{Name: "bigot", Messages: []string{"slur"}})
+	require.Len(t, both, 1)
+	require.Equal(t, []string{"racist"}, both[0].Mark)
+	require.Equal(t, []string{"suspicious"}, both[0].TransientMark)
+
+	plain := engine.MatchName("plain")
+	require.Len(t, plain, 1)
+	require.Empty(t, plain[0].Mark)
+	require.Empty(t, plain[0].TransientMark)
 }
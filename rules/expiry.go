@@ -0,0 +1,123 @@
+package rules
+
+import (
+	"time"
+)
+
+// expiryActive returns true if the unix expiry time has not yet passed. An expiry of 0 never expires.
+func expiryActive(expires int64, now time.Time) bool {
+	return expires == 0 || now.Unix() < expires
+}
+
+// activeAttributes returns the attributes of the entry which have not expired. If the entry itself has expired,
+// none of its attributes are active.
+func (p PlayerDefinition) activeAttributes(now time.Time) []string {
+	if !expiryActive(p.Expires, now) {
+		return nil
+	}
+
+	var active []string
+
+	for _, attr := range p.Attributes {
+		if expiryActive(p.AttributeExpiry[attr], now) {
+			active = append(active, attr)
+		}
+	}
+
+	return active
+}
+
+// applyExpiry sets the expiry of the newly marked attributes. Any expiry of the entry as a whole is moved onto the
+// attributes it previously covered so that it does not also expire the new attributes.
+func (p *PlayerDefinition) applyExpiry(opts MarkOpts, attrs []string) {
+	if p.Expires != 0 {
+		for _, attr := range p.Attributes {
+			if _, found := p.AttributeExpiry[attr]; !found {
+				p.setAttributeExpiry(attr, time.Unix(p.Expires, 0))
+			}
+		}
+
+		p.Expires = 0
+	}
+
+	for _, attr := range attrs {
+		expires := opts.Expires
+		if attrExpires, found := opts.AttributeExpiry[attr]; found {
+			expires = attrExpires
+		}
+
+		p.setAttributeExpiry(attr, expires)
+	}
+}
+
+func (p *PlayerDefinition) setAttributeExpiry(attr string, expires time.Time) {
+	if expires.IsZero() {
+		delete(p.AttributeExpiry, attr)
+
+		return
+	}
+
+	if p.AttributeExpiry == nil {
+		p.AttributeExpiry = map[string]int64{}
+	}
+
+	p.AttributeExpiry[attr] = expires.Unix()
+}
+
+// newPlayerSteamIDMatcher creates a matcher for the list entry which stops matching any attributes once they expire.
+func newPlayerSteamIDMatcher(origin string, player PlayerDefinition) SteamIDMatcher {
+	matcher := NewSteamIDMatcher(origin, player.SteamID, player.Attributes)
+	matcher.lastSeen = player.LastSeen
+	matcher.expires = player.Expires
+	matcher.attributeExpiry = player.AttributeExpiry
+
+	return matcher
+}
+
+// PruneExpired removes all expired attributes from the local player list, along with any entries that no longer
+// have any active attributes. The number of entries which were updated or removed is returned.
+func (e *Engine) PruneExpired(now time.Time) int {
+	e.Lock()
+	defer e.Unlock()
+
+	var (
+		userList = e.UserPlayerList()
+		players  = make([]PlayerDefinition, 0, len(userList.Players))
+		changed  int
+	)
+
+	for _, player := range userList.Players {
+		active := player.activeAttributes(now)
+		if len(active) == len(player.Attributes) {
+			players = append(players, player)
+
+			continue
+		}
+
+		changed++
+
+		userList.unregisterSteamID(player.SteamID)
+
+		if len(active) == 0 {
+			continue
+		}
+
+		expiry := map[string]int64{}
+
+		for _, attr := range active {
+			if expires, found := player.AttributeExpiry[attr]; found {
+				expiry[attr] = expires
+			}
+		}
+
+		player.Attributes = active
+		player.AttributeExpiry = expiry
+		players = append(players, player)
+
+		userList.RegisterSteamIDMatcher(newPlayerSteamIDMatcher(LocalRuleName, player))
+	}
+
+	userList.Players = players
+
+	return changed
+}
@@ -0,0 +1,57 @@
+package rules_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/bd/rules"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkExpiry(t *testing.T) {
+	var (
+		engine     = rules.New()
+		expiredID  = steamid.New(76561197961279983)
+		attrID     = steamid.New(76561197960265749)
+		permanent  = steamid.New(76561197960435530)
+		past       = time.Now().Add(-time.Hour)
+		future     = time.Now().Add(time.Hour)
+		localTitle = rules.LocalRuleName
+	)
+
+	require.NoError(t, engine.Mark(rules.MarkOpts{SteamID: expiredID, Attributes: []string{"suspicious"}, Expires: past}))
+	require.NoError(t, engine.Mark(rules.MarkOpts{
+		SteamID:         attrID,
+		Attributes:      []string{"cheater", "racist"},
+		Expires:         future,
+		AttributeExpiry: map[string]time.Time{"racist": past},
+	}))
+	require.NoError(t, engine.Mark(rules.MarkOpts{SteamID: permanent, Attributes: []string{"cheater"}}))
+
+	require.Empty(t, engine.MatchSteam(expiredID))
+
+	attrMatches := engine.MatchSteam(attrID)
+	require.Len(t, attrMatches, 1)
+	require.Equal(t, []string{"cheater"}, attrMatches[0].Attributes)
+	require.Equal(t, localTitle, attrMatches[0].Origin)
+
+	// Expired attributes can be marked again, active ones are duplicates
+	require.ErrorIs(t, engine.Mark(rules.MarkOpts{SteamID: attrID, Attributes: []string{"cheater"}}), rules.ErrDuplicateSteamID)
+	require.NoError(t, engine.Mark(rules.MarkOpts{SteamID: expiredID, Attributes: []string{"suspicious"}, Expires: future}))
+	require.Len(t, engine.MatchSteam(expiredID), 1)
+
+	require.NoError(t, engine.Mark(rules.MarkOpts{SteamID: permanent, Attributes: []string{"racist"}, Expires: past}))
+	require.Equal(t, []string{"cheater"}, engine.MatchSteam(permanent)[0].Attributes)
+
+	// Pruning after everything with an expiry has expired leaves only the permanent attributes
+	require.Equal(t, 3, engine.PruneExpired(future.Add(time.Minute)))
+	require.Equal(t, 0, engine.PruneExpired(future.Add(time.Minute)))
+
+	players := engine.UserPlayerList().Players
+	require.Len(t, players, 1)
+	require.Equal(t, permanent, players[0].SteamID)
+	require.Equal(t, []string{"cheater"}, players[0].Attributes)
+	require.Empty(t, players[0].AttributeExpiry)
+	require.Len(t, engine.MatchSteam(permanent), 1)
+}
@@ -0,0 +1,88 @@
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrDecodeList    = errors.New("failed to decode list")
+	ErrUnknownFormat = errors.New("unknown list format")
+)
+
+// ListFormat is the encoding used when reading and writing player and rules lists.
+type ListFormat string
+
+const (
+	FormatJSON ListFormat = "json"
+	FormatYAML ListFormat = "yaml"
+)
+
+// DetectFormat determines the format of a list using the extension of its file name or url. When the extension is
+// not a known format, the content is inspected instead and anything that does not look like a json object or array
+// is treated as yaml.
+func DetectFormat(name string, body []byte) ListFormat {
+	// Ignore any query string or fragment in urls
+	if idx := strings.IndexAny(name, "?#"); idx >= 0 {
+		name = name[:idx]
+	}
+
+	switch strings.ToLower(path.Ext(name)) {
+	case ".json":
+		return FormatJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	}
+
+	trimmed := bytes.TrimPrefix(bytes.TrimSpace(body), []byte("\xef\xbb\xbf"))
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return FormatJSON
+	}
+
+	return FormatYAML
+}
+
+// DecodeList decodes a PlayerListSchema or RuleSchema in the provided format.
+func DecodeList(body []byte, format ListFormat, list any) error {
+	var errDecode error
+
+	switch format {
+	case FormatJSON:
+		errDecode = json.Unmarshal(body, list)
+	case FormatYAML:
+		errDecode = yaml.Unmarshal(body, list)
+	default:
+		return ErrUnknownFormat
+	}
+
+	if errDecode != nil {
+		return errors.Join(errDecode, ErrDecodeList)
+	}
+
+	return nil
+}
+
+// EncodeList writes a PlayerListSchema or RuleSchema in the provided format.
+func EncodeList(writer io.Writer, format ListFormat, list any) error {
+	switch format {
+	case FormatJSON:
+		return newJSONPrettyEncoder(writer).Encode(list)
+	case FormatYAML:
+		enc := yaml.NewEncoder(writer)
+		enc.SetIndent(exportIndentSize)
+
+		if errEncode := enc.Encode(list); errEncode != nil {
+			return errEncode
+		}
+
+		return enc.Close()
+	default:
+		return ErrUnknownFormat
+	}
+}
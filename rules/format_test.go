@@ -0,0 +1,116 @@
+package rules_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/leighmacdonald/bd/rules"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectFormat(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		body     string
+		expected rules.ListFormat
+	}{
+		{name: "playerlist.local.json", body: "players: []", expected: rules.FormatJSON},
+		{name: "playerlist.local.yaml", body: `{"players": []}`, expected: rules.FormatYAML},
+		{name: "rules.yml", expected: rules.FormatYAML},
+		{name: "https://localhost/rules.official.json?v=1", expected: rules.FormatJSON},
+		{name: "https://localhost/export/bans", body: "\n  {\"players\": []}", expected: rules.FormatJSON},
+		{name: "playerlist.local.json.1.bak", body: "\xef\xbb\xbf[]", expected: rules.FormatJSON},
+		{name: "playerlist.local.yaml.1.bak", body: "# comment\nplayers: []", expected: rules.FormatYAML},
+	} {
+		require.Equal(t, tc.expected, rules.DetectFormat(tc.name, []byte(tc.body)), tc.name)
+	}
+}
+
+func TestYAMLLists(t *testing.T) {
+	const playerList = `
+schema: https://raw.githubusercontent.com/PazerOP/tf2_bot_detector/master/schemas/v3/playerlist.schema.json
+file_info:
+  title: yaml players
+  update_url: http://localhost/players.yaml
+players:
+  # Confirmed by multiple demos
+  - steamid: 76561197961279983
+    attributes: [cheater]
+    last_seen:
+      player_name: test
+      time: 1700000000
+  - steamid: "[U:1:2]"
+    attributes:
+      - suspicious
+    expires: 1700000000
+`
+
+	const rulesList = `
+file_info:
+  title: yaml rules
+rules:
+  - description: discord spam
+    severity: low
+    actions:
+      transient_mark: [suspicious]
+    triggers:
+      chat_msg_text_match:
+        mode: contains
+        patterns: [discord.gg]
+        attributes: [spam]
+`
+
+	engine := rules.New()
+
+	var players rules.PlayerListSchema
+
+	require.NoError(t, rules.DecodeList([]byte(playerList), rules.FormatYAML, &players))
+	require.Equal(t, "yaml players", players.FileInfo.Title)
+	require.Equal(t, "http://localhost/players.yaml", players.FileInfo.UpdateURL)
+	require.Len(t, players.Players, 2)
+	require.Equal(t, steamid.New(76561197961279983), players.Players[0].SteamID)
+	require.Equal(t, "test", players.Players[0].LastSeen.PlayerName)
+	require.Equal(t, steamid.New("[U:1:2]"), players.Players[1].SteamID)
+
+	_, errImportPlayers := engine.ImportPlayers(&players)
+	require.NoError(t, errImportPlayers)
+	require.Len(t, engine.MatchSteam(players.Players[0].SteamID), 1)
+	require.Empty(t, engine.MatchSteam(players.Players[1].SteamID))
+
+	var ruleSchema rules.RuleSchema
+
+	require.NoError(t, rules.DecodeList([]byte(rulesList), rules.FormatYAML, &ruleSchema))
+
+	_, errImportRules := engine.ImportRules(&ruleSchema)
+	require.NoError(t, errImportRules)
+
+	matches := engine.MatchMessage("join discord.gg/abc")
+	require.Len(t, matches, 1)
+	require.Equal(t, rules.SeverityLow, matches[0].Severity)
+	require.Equal(t, []string{"suspicious"}, matches[0].TransientMark)
+
+	// Exported lists decode to the same values in either format
+	for _, format := range []rules.ListFormat{rules.FormatJSON, rules.FormatYAML} {
+		var (
+			playersBuf bytes.Buffer
+			rulesBuf   bytes.Buffer
+			exported   rules.PlayerListSchema
+			exportedRl rules.RuleSchema
+		)
+
+		require.NoError(t, engine.ExportPlayers("yaml players", format, &playersBuf))
+		require.Equal(t, format, rules.DetectFormat("", playersBuf.Bytes()))
+		require.NoError(t, rules.DecodeList(playersBuf.Bytes(), format, &exported))
+		require.Equal(t, players.FileInfo.Title, exported.FileInfo.Title)
+		require.Equal(t, players.FileInfo.UpdateURL, exported.FileInfo.UpdateURL)
+		require.Equal(t, players.Players, exported.Players)
+
+		require.NoError(t, engine.ExportRules("yaml rules", format, &rulesBuf))
+		require.NoError(t, rules.DecodeList(rulesBuf.Bytes(), format, &exportedRl))
+		require.Equal(t, ruleSchema.Rules, exportedRl.Rules)
+	}
+
+	require.ErrorIs(t, rules.DecodeList([]byte("players: {"), rules.FormatYAML, &players), rules.ErrDecodeList)
+	require.ErrorIs(t, rules.DecodeList(nil, "xml", &players), rules.ErrUnknownFormat)
+}
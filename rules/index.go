@@ -0,0 +1,77 @@
+package rules
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/leighmacdonald/steamid/v4/steamid"
+)
+
+// steamIndex is the aggregated index of which loaded player lists contain entries for each steam id. This allows
+// MatchSteam to only check the lists that are known to contain the player instead of scanning every entry.
+//
+// It has its own lock so that it can be updated when matchers are registered directly against a list.
+type steamIndex struct {
+	lists map[steamid.SteamID][]*PlayerListSchema
+	mu    sync.RWMutex
+}
+
+func newSteamIndex() *steamIndex {
+	return &steamIndex{lists: map[steamid.SteamID][]*PlayerListSchema{}}
+}
+
+// add records that the list contains an entry for the steam id.
+func (idx *steamIndex) add(sid64 steamid.SteamID, list *PlayerListSchema) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if slices.Contains(idx.lists[sid64], list) {
+		return
+	}
+
+	idx.lists[sid64] = append(idx.lists[sid64], list)
+}
+
+// remove records that the list no longer contains any entries for the steam id.
+func (idx *steamIndex) remove(sid64 steamid.SteamID, list *PlayerListSchema) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	remaining := slices.DeleteFunc(idx.lists[sid64], func(known *PlayerListSchema) bool {
+		return known == list
+	})
+
+	if len(remaining) == 0 {
+		delete(idx.lists, sid64)
+
+		return
+	}
+
+	idx.lists[sid64] = remaining
+}
+
+// get returns all the lists which contain the steam id.
+func (idx *steamIndex) get(sid64 steamid.SteamID) []*PlayerListSchema {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return idx.lists[sid64]
+}
+
+// rebuild replaces the current index with one generated from the provided lists. The lists are also updated to
+// point to this index so that any future matchers registered to them are tracked.
+func (idx *steamIndex) rebuild(lists []*PlayerListSchema) {
+	entries := map[steamid.SteamID][]*PlayerListSchema{}
+
+	for _, list := range lists {
+		list.aggregate = idx
+
+		for sid64 := range list.steamIndex {
+			entries[sid64] = append(entries[sid64], list)
+		}
+	}
+
+	idx.mu.Lock()
+	idx.lists = entries
+	idx.mu.Unlock()
+}
@@ -16,11 +16,24 @@ type MatchResult struct {
 	Attributes []string `json:"attributes"`
 	// Proof       []string
 	MatcherType string `json:"matcher_type"`
+	Pattern     string `json:"pattern"`     // The specific pattern, hash or steam id that triggered the match
+	Description string `json:"description"` // Description of the rule that triggered the match, if any
+	// Similarity is the 0.0-1.0 similarity score of the text to the pattern when using TextMatchModeSimilar.
+	Similarity float64 `json:"similarity"`
+	// Mark contains the attributes the player should be added to the local player list with, if any.
+	Mark []string `json:"mark"`
+	// TransientMark contains the attributes the player should be tagged with for the current session only, if any.
+	TransientMark []string `json:"transient_mark"`
+	// Severity of the match, used along with the weight to score the player
+	Severity Severity `json:"severity"`
+	// Weight is the number of points the match adds to the players suspicion score, before any list weight is applied
+	Weight float64 `json:"weight"`
 }
 
+// HasAttr returns true if the result has the attribute, or one of its children.
 func (mr MatchResult) HasAttr(attr string) bool {
 	for _, resultAttr := range mr.Attributes {
-		if strings.EqualFold(resultAttr, attr) {
+		if attributeCovers(attr, resultAttr) {
 			return true
 		}
 	}
@@ -43,6 +56,8 @@ func (mr MatchResult) HasAttr(attr string) bool {
 const (
 	// 1:1 match of avatar
 	AvatarMatchExact AvatarMatchType = "hash_full"
+	// Perceptual (dHash) match of avatar, allowing for small differences between the images
+	AvatarMatchPerceptual AvatarMatchType = "dhash"
 	// Reduced matcher
 	// avatarMatchReduced AvatarMatchType = "hash_reduced".
 )
@@ -54,10 +69,11 @@ type AvatarMatcherHandler interface {
 }
 
 type AvatarMatcher struct {
-	matchType  AvatarMatchType
-	origin     string
-	hashes     []string
-	attributes []string
+	matchType   AvatarMatchType
+	origin      string
+	description string
+	hashes      []string
+	attributes  []string
 }
 
 func (m AvatarMatcher) Type() AvatarMatchType {
@@ -67,18 +83,26 @@ func (m AvatarMatcher) Type() AvatarMatchType {
 func (m AvatarMatcher) Match(hexDigest string) (MatchResult, bool) {
 	for _, hash := range m.hashes {
 		if hash == hexDigest {
-			return MatchResult{Origin: m.origin, MatcherType: string(m.Type()), Attributes: m.attributes}, true
+			return MatchResult{
+				Origin:      m.origin,
+				Attributes:  m.attributes,
+				MatcherType: string(m.Type()),
+				Pattern:     hash,
+				Description: m.description,
+			}, true
 		}
 	}
 
 	return MatchResult{}, false
 }
 
-func NewAvatarMatcher(origin string, avatarMatchType AvatarMatchType, hashes ...string) AvatarMatcher {
+func NewAvatarMatcher(origin string, description string, avatarMatchType AvatarMatchType, attributes []string, hashes ...string) AvatarMatcher {
 	return AvatarMatcher{
-		origin:    origin,
-		matchType: avatarMatchType,
-		hashes:    hashes,
+		origin:      origin,
+		description: description,
+		matchType:   avatarMatchType,
+		attributes:  attributes,
+		hashes:      hashes,
 	}
 }
 
@@ -98,14 +122,22 @@ type SteamIDMatcherHandler interface {
 }
 
 type SteamIDMatcher struct {
-	steamID    steamid.SteamID
-	origin     string
-	attributes []string
-	lastSeen   PlayerLastSeen
+	steamID         steamid.SteamID
+	origin          string
+	attributes      []string
+	lastSeen        PlayerLastSeen
+	expires         int64
+	attributeExpiry map[string]int64
+}
+
+// activeAttributes returns the attributes that have not yet expired.
+func (m SteamIDMatcher) activeAttributes(now time.Time) []string {
+	return PlayerDefinition{Attributes: m.attributes, Expires: m.expires, AttributeExpiry: m.attributeExpiry}.
+		activeAttributes(now)
 }
 
 func (m SteamIDMatcher) SteamID() steamid.SteamID {
-	return m.SteamID()
+	return m.steamID
 }
 
 func (m SteamIDMatcher) LastSeen() time.Time {
@@ -113,9 +145,11 @@ func (m SteamIDMatcher) LastSeen() time.Time {
 }
 
 func (m SteamIDMatcher) HasOneOfAttr(attrs ...string) bool {
+	active := m.activeAttributes(time.Now())
+
 	for _, attr := range attrs {
-		if slices.ContainsFunc(m.attributes, func(s string) bool {
-			return strings.EqualFold(attr, s)
+		if slices.ContainsFunc(active, func(s string) bool {
+			return attributeCovers(attr, s)
 		}) {
 			return true
 		}
@@ -124,29 +158,62 @@ func (m SteamIDMatcher) HasOneOfAttr(attrs ...string) bool {
 	return false
 }
 
+// Match checks if the steam id matches, only the attributes which have not expired are included in the result. Once
+// all the attributes have expired the matcher no longer matches.
 func (m SteamIDMatcher) Match(sid64 steamid.SteamID) (MatchResult, bool) {
-	if sid64 == m.steamID {
-		return MatchResult{Origin: m.origin, MatcherType: "steam_id", Attributes: m.attributes}, true
+	if sid64 != m.steamID {
+		return MatchResult{}, false
 	}
 
-	return MatchResult{}, false
+	attributes := m.attributes
+	if m.expires != 0 || len(m.attributeExpiry) > 0 {
+		attributes = m.activeAttributes(time.Now())
+		if len(attributes) == 0 {
+			return MatchResult{}, false
+		}
+	}
+
+	return MatchResult{
+		Origin:      m.origin,
+		Attributes:  attributes,
+		MatcherType: "steam_id",
+		Pattern:     m.steamID.String(),
+	}, true
 }
 
 func NewSteamIDMatcher(origin string, sid64 steamid.SteamID, attributes []string) SteamIDMatcher {
 	return SteamIDMatcher{steamID: sid64, origin: origin, attributes: attributes}
 }
 
+// compilePattern compiles the regex pattern, wrapping any error with ErrInvalidRegex. Patterns are compiled once,
+// when the matcher is created, and are kept by the matcher so that they are released along with the list.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	compiledRx, compErr := regexp.Compile(pattern)
+	if compErr != nil {
+		return nil, errors.Join(compErr, fmt.Errorf("%w: %s", ErrInvalidRegex, pattern))
+	}
+
+	return compiledRx, nil
+}
+
 type RegexTextMatcher struct {
 	matcherType TextMatchType
 	patterns    []*regexp.Regexp
 	origin      string
+	description string
 	attributes  []string
 }
 
 func (m RegexTextMatcher) Match(value string) (MatchResult, bool) {
 	for _, re := range m.patterns {
 		if re.MatchString(value) {
-			return MatchResult{Origin: m.origin, MatcherType: string(m.Type())}, true
+			return MatchResult{
+				Origin:      m.origin,
+				Attributes:  m.attributes,
+				MatcherType: string(m.Type()),
+				Pattern:     re.String(),
+				Description: m.description,
+			}, true
 		}
 	}
 
@@ -157,13 +224,13 @@ func (m RegexTextMatcher) Type() TextMatchType {
 	return m.matcherType
 }
 
-func NewRegexTextMatcher(origin string, matcherType TextMatchType, attributes []string, patterns ...string) (RegexTextMatcher, error) {
+func NewRegexTextMatcher(origin string, description string, matcherType TextMatchType, attributes []string, patterns ...string) (RegexTextMatcher, error) {
 	compiled := make([]*regexp.Regexp, len(patterns))
 
 	for index, inputPattern := range patterns {
-		compiledRx, compErr := regexp.Compile(inputPattern)
+		compiledRx, compErr := compilePattern(inputPattern)
 		if compErr != nil {
-			return RegexTextMatcher{}, errors.Join(compErr, fmt.Errorf("%w: %s", ErrInvalidRegex, inputPattern))
+			return RegexTextMatcher{}, compErr
 		}
 
 		compiled[index] = compiledRx
@@ -171,107 +238,313 @@ func NewRegexTextMatcher(origin string, matcherType TextMatchType, attributes []
 
 	return RegexTextMatcher{
 		origin:      origin,
+		description: description,
 		matcherType: matcherType,
 		patterns:    compiled,
 		attributes:  attributes,
 	}, nil
 }
 
+// DefaultSimilarityThreshold is used for TextMatchModeSimilar matchers that do not define their own threshold.
+const DefaultSimilarityThreshold = 0.8
+
 type GeneralTextMatcher struct {
 	matcherType   TextMatchType
 	mode          TextMatchMode
 	caseSensitive bool
 	patterns      []string
+	compiled      []*regexp.Regexp
+	threshold     float64
 	attributes    []string
 	origin        string
+	description   string
 }
 
-func (m GeneralTextMatcher) Match(value string) (MatchResult, bool) { //nolint:gocognit,cyclop
+// result builds the MatchResult for a successful match against the pattern provided.
+func (m GeneralTextMatcher) result(pattern string) MatchResult {
+	return MatchResult{
+		Origin:      m.origin,
+		Attributes:  m.attributes,
+		MatcherType: string(m.Type()),
+		Pattern:     pattern,
+		Description: m.description,
+	}
+}
+
+// matchPattern compares the value against a single pattern using the non-regex match modes. Any case
+// normalisation must already be applied to both values.
+func (m GeneralTextMatcher) matchPattern(value string, pattern string) bool {
 	switch m.mode {
-	case TextMatchModeRegex:
-		// Not implemented
 	case TextMatchModeStartsWith:
-		for _, prefix := range m.patterns {
-			if m.caseSensitive {
-				if strings.HasPrefix(value, prefix) {
-					return MatchResult{Origin: m.origin, Attributes: m.attributes}, true
-				}
-			} else {
-				if strings.HasPrefix(strings.ToLower(value), strings.ToLower(prefix)) {
-					return MatchResult{Origin: m.origin}, true
-				}
-			}
-		}
+		return strings.HasPrefix(value, pattern)
 	case TextMatchModeEndsWith:
-		for _, prefix := range m.patterns {
-			if m.caseSensitive {
-				if strings.HasSuffix(value, prefix) {
-					return MatchResult{Origin: m.origin}, true
-				}
-			} else {
-				if strings.HasSuffix(strings.ToLower(value), strings.ToLower(prefix)) {
-					return MatchResult{Origin: m.origin, MatcherType: string(m.Type())}, true
-				}
-			}
-		}
+		return strings.HasSuffix(value, pattern)
 	case TextMatchModeEqual:
-		for _, prefix := range m.patterns {
-			if m.caseSensitive {
-				if value == prefix {
-					return MatchResult{Origin: m.origin}, true
-				}
-			} else {
-				if strings.EqualFold(value, prefix) {
-					return MatchResult{Origin: m.origin}, true
-				}
+		return value == pattern
+	case TextMatchModeContains:
+		return strings.Contains(value, pattern)
+	case TextMatchModeWord:
+		for _, word := range strings.Split(value, " ") {
+			if word == pattern {
+				return true
 			}
 		}
-	case TextMatchModeContains:
-		for _, prefix := range m.patterns {
-			if m.caseSensitive {
-				if strings.Contains(value, prefix) {
-					return MatchResult{Origin: m.origin, MatcherType: string(m.Type())}, true
-				}
-			} else {
-				if strings.Contains(strings.ToLower(value), strings.ToLower(prefix)) {
-					return MatchResult{Origin: m.origin}, true
-				}
+	case TextMatchModeRegex, TextMatchModeSimilar:
+		// Handled separately as they do not use simple string comparisons
+	}
+
+	return false
+}
+
+func (m GeneralTextMatcher) Match(value string) (MatchResult, bool) {
+	if m.mode == TextMatchModeRegex {
+		for idx, rx := range m.compiled {
+			if rx.MatchString(value) {
+				return m.result(m.patterns[idx]), true
 			}
 		}
-	case TextMatchModeWord:
+
+		return MatchResult{}, false
+	}
+
+	if !m.caseSensitive {
+		value = strings.ToLower(value)
+	}
+
+	if m.mode == TextMatchModeSimilar {
+		return m.matchSimilar(value)
+	}
+
+	for _, pattern := range m.patterns {
+		normalised := pattern
 		if !m.caseSensitive {
-			value = strings.ToLower(value)
+			normalised = strings.ToLower(pattern)
 		}
 
-		for _, word := range strings.Split(value, " ") {
-			for _, pattern := range m.patterns {
-				if m.caseSensitive {
-					if pattern == word {
-						return MatchResult{Origin: m.origin}, true
-					}
-				} else {
-					if strings.EqualFold(strings.ToLower(pattern), word) {
-						return MatchResult{Origin: m.origin}, true
-					}
-				}
-			}
+		if m.matchPattern(value, normalised) {
+			return m.result(pattern), true
 		}
 	}
 
 	return MatchResult{}, false
 }
 
+// matchSimilar finds the pattern most similar to the value, returning a match if it meets the configured threshold.
+func (m GeneralTextMatcher) matchSimilar(value string) (MatchResult, bool) {
+	var (
+		bestScore   float64
+		bestPattern string
+	)
+
+	for _, pattern := range m.patterns {
+		normalised := pattern
+		if !m.caseSensitive {
+			normalised = strings.ToLower(pattern)
+		}
+
+		if score := Similarity(value, normalised); score > bestScore {
+			bestScore = score
+			bestPattern = pattern
+		}
+	}
+
+	if bestScore == 0 || bestScore < m.threshold {
+		return MatchResult{}, false
+	}
+
+	result := m.result(bestPattern)
+	result.Similarity = bestScore
+
+	return result, true
+}
+
+// WithThreshold returns a copy of the matcher using the similarity threshold provided for TextMatchModeSimilar.
+// Values outside the 0.0-1.0 range are ignored.
+func (m GeneralTextMatcher) WithThreshold(threshold float64) GeneralTextMatcher {
+	if threshold > 0 && threshold <= 1 {
+		m.threshold = threshold
+	}
+
+	return m
+}
+
 func (m GeneralTextMatcher) Type() TextMatchType {
 	return m.matcherType
 }
 
-func NewGeneralTextMatcher(origin string, matcherType TextMatchType, matchMode TextMatchMode, caseSensitive bool, attributes []string, patterns ...string) GeneralTextMatcher {
+// NewGeneralTextMatcher creates a new text matcher. When using TextMatchModeRegex, the patterns are compiled
+// upfront and an error wrapping ErrInvalidRegex is returned if any of them are invalid.
+func NewGeneralTextMatcher(origin string, description string, matcherType TextMatchType, matchMode TextMatchMode, caseSensitive bool, attributes []string, patterns ...string) (GeneralTextMatcher, error) {
+	var compiled []*regexp.Regexp
+
+	if matchMode == TextMatchModeRegex {
+		for _, pattern := range patterns {
+			if !caseSensitive {
+				pattern = "(?i)" + pattern
+			}
+
+			compiledRx, errCompile := compilePattern(pattern)
+			if errCompile != nil {
+				return GeneralTextMatcher{}, errCompile
+			}
+
+			compiled = append(compiled, compiledRx)
+		}
+	}
+
 	return GeneralTextMatcher{
 		origin:        origin,
+		description:   description,
 		matcherType:   matcherType,
 		mode:          matchMode,
 		caseSensitive: caseSensitive,
 		patterns:      patterns,
+		compiled:      compiled,
+		threshold:     DefaultSimilarityThreshold,
 		attributes:    attributes,
+	}, nil
+}
+
+// PlayerInput contains the known values for a player that multi trigger rules are evaluated against.
+type PlayerInput struct {
+	Name       string
+	Messages   []string
+	AvatarHash string
+	// AvatarPerceptualHash is the hash of the avatar as computed by PerceptualHash, used by perceptual avatar matchers.
+	AvatarPerceptualHash string
+}
+
+// avatarDigest returns the hash of the avatar in the format used by the matcher type.
+func avatarDigest(matchType AvatarMatchType, hash string, perceptualHash string) string {
+	if matchType == AvatarMatchPerceptual {
+		return perceptualHash
+	}
+
+	return hash
+}
+
+// RuleMatchHandler provides an interface to match a players data against all the triggers of a rule as a single unit.
+type RuleMatchHandler interface {
+	Match(input PlayerInput) (MatchResult, bool)
+	Mode() RuleTriggerMode
+}
+
+// RuleMatcher groups the individual trigger matchers of a single rule definition so that they can be evaluated
+// together.
+type RuleMatcher struct {
+	mode            RuleTriggerMode
+	origin          string
+	description     string
+	attributes      []string
+	matchersName    []TextMatchHandler
+	matchersMessage []TextMatchHandler
+	matchersAvatar  []AvatarMatcherHandler
+}
+
+func (m RuleMatcher) Mode() RuleTriggerMode {
+	return m.mode
+}
+
+func (m RuleMatcher) matchName(name string) (MatchResult, bool) {
+	if name == "" {
+		return MatchResult{}, false
+	}
+
+	for _, matcher := range m.matchersName {
+		if match, found := matcher.Match(name); found {
+			return match, true
+		}
+	}
+
+	return MatchResult{}, false
+}
+
+func (m RuleMatcher) matchMessages(messages []string) (MatchResult, bool) {
+	for _, matcher := range m.matchersMessage {
+		for _, message := range messages {
+			if match, found := matcher.Match(message); found {
+				return match, true
+			}
+		}
 	}
+
+	return MatchResult{}, false
+}
+
+func (m RuleMatcher) matchAvatar(hash string, perceptualHash string) (MatchResult, bool) {
+	for _, matcher := range m.matchersAvatar {
+		hexDigest := avatarDigest(matcher.Type(), hash, perceptualHash)
+		if hexDigest == "" {
+			continue
+		}
+
+		if match, found := matcher.Match(hexDigest); found {
+			return match, true
+		}
+	}
+
+	return MatchResult{}, false
+}
+
+// Match checks the input against each of the trigger types defined for the rule. When using RuleTriggerModeMatchAll,
+// every trigger type that is defined must match for the rule to trigger. The patterns of each of the triggers that
+// matched are included in the result.
+func (m RuleMatcher) Match(input PlayerInput) (MatchResult, bool) {
+	var (
+		checked  int
+		patterns []string
+	)
+
+	check := func(match MatchResult, found bool) {
+		checked++
+
+		if found {
+			patterns = append(patterns, match.Pattern)
+		}
+	}
+
+	if len(m.matchersName) > 0 {
+		check(m.matchName(input.Name))
+	}
+
+	if len(m.matchersMessage) > 0 {
+		check(m.matchMessages(input.Messages))
+	}
+
+	if len(m.matchersAvatar) > 0 {
+		check(m.matchAvatar(input.AvatarHash, input.AvatarPerceptualHash))
+	}
+
+	if len(patterns) == 0 || (m.mode == RuleTriggerModeMatchAll && len(patterns) != checked) {
+		return MatchResult{}, false
+	}
+
+	return MatchResult{
+		Origin:      m.origin,
+		Attributes:  m.attributes,
+		MatcherType: string(m.mode),
+		Pattern:     strings.Join(patterns, ", "),
+		Description: m.description,
+	}, true
+}
+
+func NewRuleMatcher(origin string, description string, mode RuleTriggerMode, attributes []string) *RuleMatcher {
+	return &RuleMatcher{
+		origin:      origin,
+		description: description,
+		mode:        mode,
+		attributes:  attributes,
+	}
+}
+
+func (m *RuleMatcher) RegisterNameMatcher(matcher TextMatchHandler) {
+	m.matchersName = append(m.matchersName, matcher)
+}
+
+func (m *RuleMatcher) RegisterMessageMatcher(matcher TextMatchHandler) {
+	m.matchersMessage = append(m.matchersMessage, matcher)
+}
+
+func (m *RuleMatcher) RegisterAvatarMatcher(matcher AvatarMatcherHandler) {
+	m.matchersAvatar = append(m.matchersAvatar, matcher)
 }
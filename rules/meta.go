@@ -0,0 +1,80 @@
+package rules
+
+// ruleMeta contains the values from a rule definition that are attached to each of the matches it generates.
+type ruleMeta struct {
+	actions  RuleActions
+	severity Severity
+	weight   float64
+}
+
+func newRuleMeta(rule RuleDefinition) ruleMeta {
+	return ruleMeta{actions: rule.Actions, severity: rule.Severity, weight: rule.Weight}
+}
+
+// apply attaches the rule values to the match result so that its actions can be performed, and it can be scored,
+// by the caller. Rules without a severity or weight leave them unset so that the defaults of the list are used.
+func (m ruleMeta) apply(result MatchResult) MatchResult {
+	result.Mark = m.actions.Mark
+	result.TransientMark = m.actions.TransientMark
+	result.Severity = m.severity
+	result.Weight = m.weight
+
+	return result
+}
+
+// ruleTextMatcher attaches the values of the rule it was created from to the results of the wrapped matcher.
+type ruleTextMatcher struct {
+	matcher TextMatchHandler
+	meta    ruleMeta
+}
+
+func (m ruleTextMatcher) Match(text string) (MatchResult, bool) {
+	result, found := m.matcher.Match(text)
+	if !found {
+		return result, false
+	}
+
+	return m.meta.apply(result), true
+}
+
+func (m ruleTextMatcher) Type() TextMatchType {
+	return m.matcher.Type()
+}
+
+// ruleAvatarMatcher attaches the values of the rule it was created from to the results of the wrapped matcher.
+type ruleAvatarMatcher struct {
+	matcher AvatarMatcherHandler
+	meta    ruleMeta
+}
+
+func (m ruleAvatarMatcher) Match(hexDigest string) (MatchResult, bool) {
+	result, found := m.matcher.Match(hexDigest)
+	if !found {
+		return result, false
+	}
+
+	return m.meta.apply(result), true
+}
+
+func (m ruleAvatarMatcher) Type() AvatarMatchType {
+	return m.matcher.Type()
+}
+
+// ruleGroupMatcher attaches the values of the rule it was created from to the results of the wrapped matcher.
+type ruleGroupMatcher struct {
+	matcher RuleMatchHandler
+	meta    ruleMeta
+}
+
+func (m ruleGroupMatcher) Match(input PlayerInput) (MatchResult, bool) {
+	result, found := m.matcher.Match(input)
+	if !found {
+		return result, false
+	}
+
+	return m.meta.apply(result), true
+}
+
+func (m ruleGroupMatcher) Mode() RuleTriggerMode {
+	return m.matcher.Mode()
+}
@@ -0,0 +1,101 @@
+package rules
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// confusables maps commonly abused look-alike characters from other scripts onto their latin equivalent. This is
+// not meant to be exhaustive, only to cover the characters that are seen being used to evade name and chat rules.
+var confusables = map[rune]rune{ //nolint:gochecknoglobals
+	// Cyrillic lower
+	'а': 'a', 'в': 'b', 'е': 'e', 'к': 'k', 'м': 'm', 'н': 'h', 'о': 'o', 'р': 'p', 'с': 'c', 'т': 't', 'у': 'y',
+	'х': 'x', 'ѕ': 's', 'і': 'i', 'ј': 'j', 'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w', 'һ': 'h', 'ӏ': 'l', 'ь': 'b',
+	// Cyrillic upper
+	'А': 'A', 'В': 'B', 'Е': 'E', 'К': 'K', 'М': 'M', 'Н': 'H', 'О': 'O', 'Р': 'P', 'С': 'C', 'Т': 'T', 'У': 'Y',
+	'Х': 'X', 'Ѕ': 'S', 'І': 'I', 'Ј': 'J', 'Ԛ': 'Q', 'Ԝ': 'W', 'Ӏ': 'I',
+	// Greek lower
+	'α': 'a', 'ε': 'e', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o', 'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x',
+	// Greek upper
+	'Α': 'A', 'Β': 'B', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'H', 'Ι': 'I', 'Κ': 'K', 'Μ': 'M', 'Ν': 'N', 'Ο': 'O', 'Ρ': 'P',
+	'Τ': 'T', 'Υ': 'Y', 'Χ': 'X', 'Λ': 'A',
+	// Latin extensions
+	'ı': 'i', 'ɡ': 'g', 'ɑ': 'a', 'ʏ': 'y', 'ɪ': 'i', 'ᴏ': 'o', 'ᴄ': 'c', 'ᴠ': 'v', 'ᴡ': 'w', 'ᴢ': 'z',
+}
+
+// isInvisible returns true for characters which render as blank or not at all. These are used to create
+// names that look identical to another player, or to break up words so that they don't match.
+func isInvisible(r rune) bool {
+	switch r {
+	case '\u115f', '\u1160', '\u3164', '\uffa0', // Hangul fillers
+		'\u2800', // Braille blank
+		'\u034f', // Combining grapheme joiner
+		'\u180e': // Mongolian vowel separator
+		return true
+	}
+
+	// Cf covers zero width spaces/joiners, bidi controls, soft hyphens and tag characters.
+	return unicode.Is(unicode.Cf, r) || unicode.Is(unicode.Variation_Selector, r)
+}
+
+// NormalizeText folds text into a canonical form that can be matched against plain patterns. The following
+// steps are applied:
+//
+//   - NFKC compatibility decomposition, folding fullwidth and mathematical letter forms into their plain variants
+//   - Removal of combining marks (diacritics) and invisible characters
+//   - Mapping of look-alike characters from other scripts onto latin letters
+func NormalizeText(text string) string {
+	var builder strings.Builder
+
+	builder.Grow(len(text))
+
+	for _, char := range norm.NFKD.String(text) {
+		if unicode.Is(unicode.Mn, char) || isInvisible(char) {
+			continue
+		}
+
+		if replacement, found := confusables[char]; found {
+			char = replacement
+		}
+
+		builder.WriteRune(char)
+	}
+
+	return norm.NFKC.String(builder.String())
+}
+
+// normalizePatterns applies NormalizeText to each of the patterns, so that patterns containing diacritics or
+// look-alike characters can still match the normalised input text. Regex patterns are left as written, since
+// folding the characters of a pattern can change its meaning, such as the bounds of a character range.
+func normalizePatterns(mode TextMatchMode, patterns []string) []string {
+	if mode == TextMatchModeRegex {
+		return patterns
+	}
+
+	normalized := make([]string, len(patterns))
+	for index, pattern := range patterns {
+		normalized[index] = NormalizeText(pattern)
+	}
+
+	return normalized
+}
+
+// NormalizedTextMatcher wraps another TextMatchHandler, normalising the input text using NormalizeText before
+// it's passed to the wrapped matcher.
+type NormalizedTextMatcher struct {
+	matcher TextMatchHandler
+}
+
+func (m NormalizedTextMatcher) Match(text string) (MatchResult, bool) {
+	return m.matcher.Match(NormalizeText(text))
+}
+
+func (m NormalizedTextMatcher) Type() TextMatchType {
+	return m.matcher.Type()
+}
+
+func NewNormalizedTextMatcher(matcher TextMatchHandler) NormalizedTextMatcher {
+	return NormalizedTextMatcher{matcher: matcher}
+}
@@ -0,0 +1,117 @@
+package rules_test
+
+import (
+	"testing"
+
+	"github.com/leighmacdonald/bd/rules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeText(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{input: "OMEGATRONIC", expected: "OMEGATRONIC"},
+		{input: "ＯＭＥＧＡＴＲＯＮＩＣ", expected: "OMEGATRONIC"},                         // Fullwidth
+		{input: "OMEGΑTRONIC", expected: "OMEGATRONIC"},                         // Greek alpha
+		{input: "ОМЕГАТRОNIC", expected: "OMEГATRONIC"},                         // Cyrillic, Г has no latin look-alike
+		{input: "OMEG\u200bATRON\u200dIC", expected: "OMEGATRONIC"},             // Zero width space & joiner
+		{input: "O\u0308MEGATRO\u0301NIC", expected: "OMEGATRONIC"},             // Combining marks
+		{input: "[VΛC] MYG)T", expected: "[VAC] MYG)T"},                         // Lambda
+		{input: "𝐌𝐘𝐆)𝐓", expected: "MYG)T"},                                     // Mathematical bold
+		{input: "DоеsHоttеr", expected: "DoesHotter"},                           // Cyrillic lower
+		{input: "\u3164\u3164swordstoned", expected: "swordstoned"},             // Hangul filler
+		{input: "\u202eUncle Dane\u202c", expected: "Uncle Dane"},               // Bidi override
+		{input: "Sneeki Breeki\U000e0020\U000e0020", expected: "Sneeki Breeki"}, // Tag characters
+		{input: "Ünicode Fän", expected: "Unicode Fan"},
+	}
+
+	for num, testCase := range testCases {
+		require.Equal(t, testCase.expected, rules.NormalizeText(testCase.input), "Test %d failed", num)
+	}
+}
+
+func TestNormalizedRules(t *testing.T) {
+	engine := rules.New()
+	list := rules.RuleSchema{
+		BaseSchema: rules.BaseSchema{FileInfo: rules.FileInfo{Title: customListTitle}},
+		Rules: []rules.RuleDefinition{
+			{
+				Description: "omega bots",
+				Triggers: rules.RuleTriggers{
+					UsernameTextMatch: &rules.RuleTriggerNameMatch{
+						Mode:      rules.TextMatchModeContains,
+						Patterns:  []string{"omegatronic"},
+						Normalize: true,
+					},
+				},
+			},
+			{
+				Description: "myg)t bots",
+				Triggers: rules.RuleTriggers{
+					UsernameTextMatch: &rules.RuleTriggerNameMatch{
+						CaseSensitive: true,
+						Mode:          rules.TextMatchModeRegex,
+						Patterns:      []string{`^\[VAC\] MYG\)T$`},
+						Normalize:     true,
+					},
+				},
+			},
+			{
+				Description: "spam",
+				Triggers: rules.RuleTriggers{
+					ChatMsgTextMatch: &rules.RuleTriggerTextMatch{
+						Mode:      rules.TextMatchModeWord,
+						Patterns:  []string{"discord.gg"},
+						Normalize: true,
+					},
+				},
+			},
+			{
+				Description: "accented pattern",
+				Triggers: rules.RuleTriggers{
+					UsernameTextMatch: &rules.RuleTriggerNameMatch{
+						Mode:      rules.TextMatchModeEqual,
+						Patterns:  []string{"café ｂｏｔ"},
+						Normalize: true,
+					},
+				},
+			},
+			{
+				Description: "cyrillic name",
+				Triggers: rules.RuleTriggers{
+					UsernameTextMatch: &rules.RuleTriggerNameMatch{
+						CaseSensitive: true,
+						Mode:          rules.TextMatchModeRegex,
+						Patterns:      []string{`^[а-я]+$`},
+						Normalize:     true,
+					},
+				},
+			},
+			{
+				Description: "not normalized",
+				Triggers: rules.RuleTriggers{
+					UsernameTextMatch: &rules.RuleTriggerNameMatch{
+						Mode:     rules.TextMatchModeEqual,
+						Patterns: []string{"doeshotter"},
+					},
+				},
+			},
+		},
+	}
+
+	_, errImport := engine.ImportRules(&list)
+	require.NoError(t, errImport)
+
+	require.NotNil(t, engine.MatchName("ＯＭＥＧＡＴＲＯＮＩＣ"))
+	require.NotNil(t, engine.MatchName("(1)OMEG\u200bΑTRONIC"))
+	require.NotNil(t, engine.MatchName("[VΛC] 𝐌𝐘𝐆)𝐓"))
+	require.NotNil(t, engine.MatchMessage("join ｄｉｓｃｏｒｄ．ｇｇ now"))
+	require.NotNil(t, engine.MatchName("café bot"))
+	require.NotNil(t, engine.MatchName("cafe bot"))
+	require.Nil(t, engine.MatchName("Uncle Dane"))
+	require.Nil(t, engine.MatchName("regular"), "Regex patterns should not be normalized")
+	require.NotNil(t, engine.MatchName("DoesHotter"))
+	require.Nil(t, engine.MatchName("DоеsHоttеr"), "Rules without normalize should not fold characters")
+}
@@ -0,0 +1,171 @@
+package rules
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg" // Register decoders for the formats that steam avatars are served as
+	_ "image/png"
+	"math/bits"
+	"strconv"
+)
+
+var (
+	ErrDecodeAvatar      = errors.New("failed to decode avatar image")
+	ErrInvalidAvatarHash = errors.New("invalid avatar hash")
+)
+
+const (
+	// DefaultPerceptualDistance is the default max number of bits, out of 64, that may differ between two
+	// perceptual hashes for them to be considered the same image.
+	DefaultPerceptualDistance = 10
+
+	perceptualHashBits = 64
+	dHashWidth         = 9
+	dHashHeight        = 8
+)
+
+// PerceptualHash computes the 64bit difference hash (dHash) of the image, returned as a hex string. Unlike a
+// cryptographic hash, images which look similar, such as the same avatar after being recompressed or resized,
+// produce hashes that only differ by a small number of bits.
+//
+// The image is reduced to a 9x8 grayscale image and each bit records whether a pixel is brighter than its
+// neighbour to the right.
+func PerceptualHash(avatar []byte) (string, error) {
+	img, _, errDecode := image.Decode(bytes.NewReader(avatar))
+	if errDecode != nil {
+		return "", errors.Join(errDecode, ErrDecodeAvatar)
+	}
+
+	var (
+		pixels = reduceGray(img, dHashWidth, dHashHeight)
+		hash   uint64
+	)
+
+	for y := 0; y < dHashHeight; y++ {
+		for x := 0; x < dHashWidth-1; x++ {
+			hash <<= 1
+
+			if pixels[y*dHashWidth+x] > pixels[y*dHashWidth+x+1] {
+				hash |= 1
+			}
+		}
+	}
+
+	return fmt.Sprintf("%016x", hash), nil
+}
+
+// reduceGray scales the image down to the provided size, averaging the luminance of all the source pixels covered
+// by each of the output pixels.
+func reduceGray(img image.Image, width int, height int) []float64 {
+	var (
+		bounds = img.Bounds()
+		output = make([]float64, width*height)
+	)
+
+	for outY := 0; outY < height; outY++ {
+		minY := bounds.Min.Y + outY*bounds.Dy()/height
+		maxY := max(bounds.Min.Y+(outY+1)*bounds.Dy()/height, minY+1)
+
+		for outX := 0; outX < width; outX++ {
+			minX := bounds.Min.X + outX*bounds.Dx()/width
+			maxX := max(bounds.Min.X+(outX+1)*bounds.Dx()/width, minX+1)
+
+			var (
+				total float64
+				count int
+			)
+
+			for y := minY; y < maxY && y < bounds.Max.Y; y++ {
+				for x := minX; x < maxX && x < bounds.Max.X; x++ {
+					gray, _ := color.Gray16Model.Convert(img.At(x, y)).(color.Gray16)
+					total += float64(gray.Y)
+					count++
+				}
+			}
+
+			if count > 0 {
+				output[outY*width+outX] = total / float64(count)
+			}
+		}
+	}
+
+	return output
+}
+
+func parsePerceptualHash(hash string) (uint64, error) {
+	value, errParse := strconv.ParseUint(hash, 16, perceptualHashBits)
+	if errParse != nil {
+		return 0, errors.Join(errParse, fmt.Errorf("%w: %s", ErrInvalidAvatarHash, hash))
+	}
+
+	return value, nil
+}
+
+// PerceptualAvatarMatcher matches avatars using their perceptual hash, allowing for small differences between
+// the images such as those caused by recompression or resizing.
+type PerceptualAvatarMatcher struct {
+	origin      string
+	description string
+	attributes  []string
+	hashes      []uint64
+	distance    int
+}
+
+func (m PerceptualAvatarMatcher) Type() AvatarMatchType {
+	return AvatarMatchPerceptual
+}
+
+// Match compares the perceptual hash of an avatar, as returned by PerceptualHash, against the known hashes.
+func (m PerceptualAvatarMatcher) Match(hexDigest string) (MatchResult, bool) {
+	digest, errParse := parsePerceptualHash(hexDigest)
+	if errParse != nil {
+		return MatchResult{}, false
+	}
+
+	for _, hash := range m.hashes {
+		distance := bits.OnesCount64(hash ^ digest)
+		if distance > m.distance {
+			continue
+		}
+
+		return MatchResult{
+			Origin:      m.origin,
+			Attributes:  m.attributes,
+			MatcherType: string(m.Type()),
+			Pattern:     fmt.Sprintf("%016x", hash),
+			Description: m.description,
+			Similarity:  1 - float64(distance)/perceptualHashBits,
+		}, true
+	}
+
+	return MatchResult{}, false
+}
+
+// NewPerceptualAvatarMatcher creates a matcher for the perceptual hashes. Avatars match when their hash differs by
+// at most distance bits. A distance of 0 or less uses DefaultPerceptualDistance.
+func NewPerceptualAvatarMatcher(origin string, description string, attributes []string, distance int, hashes ...string) (PerceptualAvatarMatcher, error) {
+	if distance <= 0 {
+		distance = DefaultPerceptualDistance
+	}
+
+	matcher := PerceptualAvatarMatcher{
+		origin:      origin,
+		description: description,
+		attributes:  attributes,
+		distance:    distance,
+	}
+
+	for _, hash := range hashes {
+		value, errParse := parsePerceptualHash(hash)
+		if errParse != nil {
+			return PerceptualAvatarMatcher{}, errParse
+		}
+
+		matcher.hashes = append(matcher.hashes, value)
+	}
+
+	return matcher, nil
+}
@@ -0,0 +1,106 @@
+package rules_test
+
+import (
+	"encoding/json"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/leighmacdonald/bd/rules"
+	"github.com/stretchr/testify/require"
+)
+
+func readAvatarFixture(t *testing.T, name string) []byte {
+	t.Helper()
+
+	avatar, errRead := os.ReadFile(filepath.Join("testdata", "avatars", name))
+	require.NoError(t, errRead)
+
+	return avatar
+}
+
+func perceptualDistance(t *testing.T, hashA string, hashB string) int {
+	t.Helper()
+
+	valueA, errA := strconv.ParseUint(hashA, 16, 64)
+	require.NoError(t, errA)
+
+	valueB, errB := strconv.ParseUint(hashB, 16, 64)
+	require.NoError(t, errB)
+
+	return bits.OnesCount64(valueA ^ valueB)
+}
+
+func TestPerceptualHash(t *testing.T) {
+	botHash, errHash := rules.PerceptualHash(readAvatarFixture(t, "bot.jpg"))
+	require.NoError(t, errHash)
+	require.Len(t, botHash, 16)
+
+	for _, name := range []string{"bot_recompressed.jpg", "bot_resized.png"} {
+		hash, errVariant := rules.PerceptualHash(readAvatarFixture(t, name))
+		require.NoError(t, errVariant)
+		require.LessOrEqual(t, perceptualDistance(t, botHash, hash), rules.DefaultPerceptualDistance, name)
+	}
+
+	otherHash, errOther := rules.PerceptualHash(readAvatarFixture(t, "other.jpg"))
+	require.NoError(t, errOther)
+	require.Greater(t, perceptualDistance(t, botHash, otherHash), rules.DefaultPerceptualDistance)
+
+	_, errDecode := rules.PerceptualHash([]byte("not an image"))
+	require.ErrorIs(t, errDecode, rules.ErrDecodeAvatar)
+}
+
+func TestPerceptualAvatarRules(t *testing.T) {
+	var (
+		engine = rules.New()
+		bot    = readAvatarFixture(t, "bot.jpg")
+	)
+
+	botHash, errHash := rules.PerceptualHash(bot)
+	require.NoError(t, errHash)
+
+	// Rules are defined using the schema to ensure the perceptual triggers are serialisable
+	body, errEncode := json.Marshal(rules.RuleSchema{
+		BaseSchema: rules.BaseSchema{FileInfo: rules.FileInfo{Title: customListTitle}},
+		Rules: []rules.RuleDefinition{
+			{
+				Description: "bot avatar",
+				Triggers: rules.RuleTriggers{
+					AvatarMatch: []rules.RuleTriggerAvatarMatch{
+						{AvatarHash: botHash, Mode: rules.AvatarMatchPerceptual, Distance: 6},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, errEncode)
+
+	var list rules.RuleSchema
+
+	require.NoError(t, json.Unmarshal(body, &list))
+	require.Equal(t, rules.AvatarMatchPerceptual, list.Rules[0].Triggers.AvatarMatch[0].Mode)
+	require.Equal(t, 6, list.Rules[0].Triggers.AvatarMatch[0].Distance)
+
+	_, errImport := engine.ImportRules(&list)
+	require.NoError(t, errImport)
+
+	for _, name := range []string{"bot.jpg", "bot_recompressed.jpg", "bot_resized.png"} {
+		matches := engine.MatchAvatar(readAvatarFixture(t, name))
+		require.Len(t, matches, 1, name)
+		require.Equal(t, string(rules.AvatarMatchPerceptual), matches[0].MatcherType)
+		require.Equal(t, botHash, matches[0].Pattern)
+		require.Greater(t, matches[0].Similarity, 0.9)
+	}
+
+	require.Empty(t, engine.MatchAvatar(readAvatarFixture(t, "other.jpg")))
+
+	// The exact hash of the original should not match the recompressed version
+	exact := rules.NewAvatarMatcher(customListTitle, "", rules.AvatarMatchExact, []string{"bot"}, rules.HashAvatar(bot))
+	_, found := exact.Match(rules.HashAvatar(readAvatarFixture(t, "bot_recompressed.jpg")))
+	require.False(t, found)
+
+	_, errInvalid := rules.NewPerceptualAvatarMatcher(customListTitle, "", nil, 0, "not a hash")
+	require.ErrorIs(t, errInvalid, rules.ErrInvalidAvatarHash)
+}
@@ -0,0 +1,207 @@
+package rules
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/leighmacdonald/steamid/v4/steamid"
+)
+
+// ListEntry is the entry for a player from a single list.
+type ListEntry struct {
+	List       string   `json:"list"`
+	Attributes []string `json:"attributes"`
+}
+
+// ListOverlap contains the entries for a player that is either listed in more than one list, or is whitelisted
+// while being listed.
+type ListOverlap struct {
+	SteamID steamid.SteamID `json:"steam_id"`
+	Entries []ListEntry     `json:"entries"`
+	// Conflict is true when the lists disagree on the attributes of the player, or the player is whitelisted.
+	Conflict    bool `json:"conflict"`
+	Whitelisted bool `json:"whitelisted"`
+}
+
+// ListDuplicate is a player with more than one entry within the same list.
+type ListDuplicate struct {
+	SteamID steamid.SteamID `json:"steam_id"`
+	List    string          `json:"list"`
+	Count   int             `json:"count"`
+}
+
+// ListSummary contains the counts for a single player list.
+type ListSummary struct {
+	Title     string `json:"title"`
+	SourceURL string `json:"source_url"`
+	// Entries is the number of active entries in the list
+	Entries int `json:"entries"`
+	// Unique is the number of players that are only listed in this list
+	Unique int `json:"unique"`
+	// Overlaps is the number of players that are also listed in other lists
+	Overlaps int `json:"overlaps"`
+	// Conflicts is the number of players where this list disagrees with another list or the whitelist
+	Conflicts int `json:"conflicts"`
+	// Duplicates is the number of players with more than one entry in this list
+	Duplicates int `json:"duplicates"`
+	// Whitelisted is the number of players in the list that are whitelisted
+	Whitelisted int `json:"whitelisted"`
+	// Attributes is the number of entries with each attribute
+	Attributes map[string]int `json:"attributes"`
+}
+
+// ListReport describes how the loaded player lists relate to each other, which can be used to help decide how much
+// each list can be trusted.
+type ListReport struct {
+	Lists      []ListSummary   `json:"lists"`
+	Overlaps   []ListOverlap   `json:"overlaps"`
+	Duplicates []ListDuplicate `json:"duplicates"`
+}
+
+type reportEntry struct {
+	list       int
+	attributes []string
+}
+
+// ListReport compares all the loaded player lists, including the local list, finding players listed in more than
+// one list along with any that the lists disagree on. Players in the whitelist are always considered a conflict.
+// Expired entries are ignored.
+func (e *Engine) ListReport(whitelist steamid.Collection) ListReport {
+	e.RLock()
+	defer e.RUnlock()
+
+	var (
+		now       = time.Now()
+		entries   = map[steamid.SteamID][]reportEntry{}
+		summaries = make([]ListSummary, len(e.playerLists))
+		report    = ListReport{Overlaps: []ListOverlap{}, Duplicates: []ListDuplicate{}}
+	)
+
+	for listIdx, list := range e.playerLists {
+		var (
+			summary = &summaries[listIdx]
+			counts  = map[steamid.SteamID]int{}
+		)
+
+		summary.Title = list.FileInfo.Title
+		summary.SourceURL = list.SourceURL
+		summary.Attributes = map[string]int{}
+
+		for _, player := range list.Players {
+			attributes := player.activeAttributes(now)
+			if len(attributes) == 0 {
+				continue
+			}
+
+			summary.Entries++
+			counts[player.SteamID]++
+
+			for _, attr := range attributes {
+				summary.Attributes[strings.ToLower(attr)]++
+			}
+
+			// Duplicates within the same list are merged into the first entry for the player
+			if counts[player.SteamID] > 1 {
+				known := &entries[player.SteamID][len(entries[player.SteamID])-1]
+				known.attributes = append(known.attributes, attributes...)
+
+				continue
+			}
+
+			entries[player.SteamID] = append(entries[player.SteamID], reportEntry{list: listIdx, attributes: attributes})
+		}
+
+		for sid64, count := range counts {
+			if count > 1 {
+				summary.Duplicates++
+				report.Duplicates = append(report.Duplicates, ListDuplicate{SteamID: sid64, List: summary.Title, Count: count})
+			}
+		}
+	}
+
+	for sid64, playerEntries := range entries {
+		whitelisted := slices.Contains(whitelist, sid64)
+
+		if len(playerEntries) == 1 && !whitelisted {
+			summaries[playerEntries[0].list].Unique++
+
+			continue
+		}
+
+		overlap := ListOverlap{
+			SteamID:     sid64,
+			Conflict:    whitelisted || attributesDiffer(playerEntries),
+			Whitelisted: whitelisted,
+		}
+
+		for _, entry := range playerEntries {
+			summary := &summaries[entry.list]
+
+			if len(playerEntries) > 1 {
+				summary.Overlaps++
+			} else {
+				summary.Unique++
+			}
+
+			if overlap.Conflict {
+				summary.Conflicts++
+			}
+
+			if whitelisted {
+				summary.Whitelisted++
+			}
+
+			overlap.Entries = append(overlap.Entries, ListEntry{List: summary.Title, Attributes: entry.attributes})
+		}
+
+		report.Overlaps = append(report.Overlaps, overlap)
+	}
+
+	sort.Slice(report.Overlaps, func(i, j int) bool {
+		return report.Overlaps[i].SteamID.Int64() < report.Overlaps[j].SteamID.Int64()
+	})
+
+	sort.Slice(report.Duplicates, func(i, j int) bool {
+		if report.Duplicates[i].List != report.Duplicates[j].List {
+			return report.Duplicates[i].List < report.Duplicates[j].List
+		}
+
+		return report.Duplicates[i].SteamID.Int64() < report.Duplicates[j].SteamID.Int64()
+	})
+
+	report.Lists = summaries
+
+	return report
+}
+
+// attributesDiffer returns true if any of the entries have a different set of attributes, ignoring case and order.
+func attributesDiffer(entries []reportEntry) bool {
+	var first []string
+
+	for idx, entry := range entries {
+		attributes := make([]string, 0, len(entry.attributes))
+
+		for _, attr := range entry.attributes {
+			attr = strings.ToLower(attr)
+			if !slices.Contains(attributes, attr) {
+				attributes = append(attributes, attr)
+			}
+		}
+
+		slices.Sort(attributes)
+
+		if idx == 0 {
+			first = attributes
+
+			continue
+		}
+
+		if !slices.Equal(first, attributes) {
+			return true
+		}
+	}
+
+	return false
+}
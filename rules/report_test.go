@@ -0,0 +1,81 @@
+package rules_test
+
+import (
+	"testing"
+
+	"github.com/leighmacdonald/bd/rules"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListReport(t *testing.T) {
+	var (
+		engine      = rules.New()
+		agreed      = steamid.New(76561197961279983)
+		disputed    = steamid.New(76561197960265749)
+		whitelisted = steamid.New(76561197960435530)
+		uniqueID    = steamid.New(76561198084134025)
+	)
+
+	_, errA := engine.ImportPlayers(&rules.PlayerListSchema{
+		BaseSchema: rules.BaseSchema{FileInfo: rules.FileInfo{Title: "list a"}, SourceURL: "http://localhost/a.json"},
+		Players: []rules.PlayerDefinition{
+			{SteamID: agreed, Attributes: []string{"cheater"}},
+			{SteamID: disputed, Attributes: []string{"cheater"}},
+			{SteamID: whitelisted, Attributes: []string{"bot"}},
+			{SteamID: uniqueID, Attributes: []string{"racist"}},
+			{SteamID: uniqueID, Attributes: []string{"cheater"}},
+		},
+	})
+	require.NoError(t, errA)
+
+	_, errB := engine.ImportPlayers(&rules.PlayerListSchema{
+		BaseSchema: rules.BaseSchema{FileInfo: rules.FileInfo{Title: "list b"}},
+		Players: []rules.PlayerDefinition{
+			{SteamID: agreed, Attributes: []string{"Cheater"}},
+			{SteamID: disputed, Attributes: []string{"suspicious"}},
+		},
+	})
+	require.NoError(t, errB)
+
+	report := engine.ListReport(steamid.Collection{whitelisted})
+
+	require.Len(t, report.Overlaps, 3)
+
+	overlaps := map[steamid.SteamID]rules.ListOverlap{}
+	for _, overlap := range report.Overlaps {
+		overlaps[overlap.SteamID] = overlap
+	}
+
+	require.False(t, overlaps[agreed].Conflict)
+	require.Len(t, overlaps[agreed].Entries, 2)
+	require.True(t, overlaps[disputed].Conflict)
+	require.True(t, overlaps[whitelisted].Conflict)
+	require.True(t, overlaps[whitelisted].Whitelisted)
+	require.Len(t, overlaps[whitelisted].Entries, 1)
+
+	require.Equal(t, []rules.ListDuplicate{{SteamID: uniqueID, List: "list a", Count: 2}}, report.Duplicates)
+
+	summaries := map[string]rules.ListSummary{}
+	for _, summary := range report.Lists {
+		summaries[summary.Title] = summary
+	}
+
+	require.Contains(t, summaries, rules.LocalRuleName)
+
+	listA := summaries["list a"]
+	require.Equal(t, "http://localhost/a.json", listA.SourceURL)
+	require.Equal(t, 5, listA.Entries)
+	require.Equal(t, 2, listA.Unique)
+	require.Equal(t, 2, listA.Overlaps)
+	require.Equal(t, 2, listA.Conflicts)
+	require.Equal(t, 1, listA.Duplicates)
+	require.Equal(t, 1, listA.Whitelisted)
+	require.Equal(t, 3, listA.Attributes["cheater"])
+
+	listB := summaries["list b"]
+	require.Equal(t, 2, listB.Entries)
+	require.Equal(t, 0, listB.Unique)
+	require.Equal(t, 2, listB.Overlaps)
+	require.Equal(t, 1, listB.Conflicts)
+}
@@ -4,10 +4,12 @@
 
 type RuleTriggerMode string
 
-// const (
-// modeTrigMatchAny RuleTriggerMode = "match_any"
-// modeTrigMatchAll RuleTriggerMode = "match_all"
-// )
+const (
+	// RuleTriggerModeMatchAny will trigger the rule when any one of the defined triggers match.
+	RuleTriggerModeMatchAny RuleTriggerMode = "match_any"
+	// RuleTriggerModeMatchAll will only trigger the rule when every one of the defined triggers match.
+	RuleTriggerModeMatchAll RuleTriggerMode = "match_all"
+)
 
 const (
 	LocalRuleName   = "local"
@@ -25,18 +27,55 @@
 	TextMatchModeStartsWith TextMatchMode = "starts_with"
 	TextMatchModeEndsWith   TextMatchMode = "ends_with"
 	TextMatchModeWord       TextMatchMode = "word" // not really needed?
+	// TextMatchModeSimilar matches text within an edit distance based similarity threshold of the pattern. This
+	// is not part of the TF2BD schema.
+	TextMatchModeSimilar TextMatchMode = "similar"
 )
 
 type BaseSchema struct {
 	Schema   string   `json:"$schema" yaml:"schema"` //nolint:tagliatelle
 	FileInfo FileInfo `json:"file_info" yaml:"file_info"`
+	// SourceURL is the url the list was downloaded from. This is not always the same as the update url defined
+	// by the list itself.
+	SourceURL string `json:"-" yaml:"-"`
+	// Weight is the multiplier applied to the score of any matches against the list. Values of 0 or less are
+	// treated as 1.
+	Weight float64 `json:"-" yaml:"-"`
+	// Severity is used for matches against the list which do not have a severity defined by their rule. When empty,
+	// player list matches use the severity of their attributes and rule matches use SeverityMedium.
+	Severity Severity `json:"-" yaml:"-"`
+}
+
+// matches returns true if the title, update url or source url of the list is equal to the value.
+func (s BaseSchema) matches(titleOrURL string) bool {
+	if titleOrURL == "" {
+		return false
+	}
+
+	return s.FileInfo.Title == titleOrURL || s.FileInfo.UpdateURL == titleOrURL || s.SourceURL == titleOrURL
+}
+
+// isLocal returns true if the schema is one of the users local lists. Lists loaded from a url are never local, even
+// when they use the same title.
+func (s BaseSchema) isLocal() bool {
+	return s.FileInfo.Title == LocalRuleName && s.SourceURL == ""
+}
+
+// sameList returns true if both schemas refer to the same list. Unrelated lists may share a title, so lists are only
+// the same when loaded from the same source url. The local lists have no source and are only the same as each other.
+func (s BaseSchema) sameList(other BaseSchema) bool {
+	if s.isLocal() || other.isLocal() {
+		return s.isLocal() && other.isLocal()
+	}
+
+	return s.SourceURL != "" && s.SourceURL == other.SourceURL
 }
 
 type FileInfo struct {
-	Authors     []string `json:"authors"`
-	Description string   `json:"description"`
-	Title       string   `json:"title"`
-	UpdateURL   string   `json:"update_url"`
+	Authors     []string `json:"authors" yaml:"authors"`
+	Description string   `json:"description" yaml:"description"`
+	Title       string   `json:"title" yaml:"title"`
+	UpdateURL   string   `json:"update_url" yaml:"update_url"`
 }
 
 func NewPlayerListSchema(players ...PlayerDefinition) *PlayerListSchema {
@@ -79,10 +118,11 @@ func NewRuleSchema(rules ...RuleDefinition) *RuleSchema {
 }
 
 type RuleSchema struct {
-	BaseSchema
+	BaseSchema     `yaml:",inline"`
 	Rules          []RuleDefinition       `json:"rules" yaml:"rules"`
 	MatchersText   []TextMatchHandler     `json:"-" yaml:"-"`
 	MatchersAvatar []AvatarMatcherHandler `json:"-" yaml:"-"`
+	MatchersRule   []RuleMatchHandler     `json:"-" yaml:"-"`
 }
 
 type RuleTriggerNameMatch struct {
@@ -90,53 +130,116 @@ type RuleTriggerNameMatch struct {
 	Mode          TextMatchMode `json:"mode" yaml:"mode"`
 	Patterns      []string      `json:"patterns" yaml:"patterns"`
 	Attributes    []string      `json:"attributes" yaml:"attributes"` // New
+	// Normalize folds look-alike unicode characters into their plain form before matching. See NormalizeText.
+	Normalize bool `json:"normalize,omitempty" yaml:"normalize,omitempty"` // New
+	// Threshold is the minimum similarity, from 0.0-1.0, required when using TextMatchModeSimilar.
+	Threshold float64 `json:"threshold,omitempty" yaml:"threshold,omitempty"` // New
 }
 
 type RuleTriggerAvatarMatch struct {
-	AvatarHash string `json:"avatar_hash"`
+	AvatarHash string `json:"avatar_hash" yaml:"avatar_hash"`
+	// Mode selects how the avatar is compared, defaults to AvatarMatchExact when empty
+	Mode AvatarMatchType `json:"mode,omitempty" yaml:"mode,omitempty"`
+	// Distance is the max hamming distance allowed when using AvatarMatchPerceptual
+	Distance int `json:"distance,omitempty" yaml:"distance,omitempty"`
 }
 
 type RuleTriggerTextMatch struct {
-	CaseSensitive bool          `json:"case_sensitive"`
-	Mode          TextMatchMode `json:"mode"`
-	Patterns      []string      `json:"patterns"`
+	CaseSensitive bool          `json:"case_sensitive" yaml:"case_sensitive"`
+	Mode          TextMatchMode `json:"mode" yaml:"mode"`
+	Patterns      []string      `json:"patterns" yaml:"patterns"`
 	Attributes    []string      `json:"attributes" yaml:"attributes"` // New
+	// Normalize folds look-alike unicode characters into their plain form before matching. See NormalizeText.
+	Normalize bool `json:"normalize,omitempty" yaml:"normalize,omitempty"` // New
+	// Threshold is the minimum similarity, from 0.0-1.0, required when using TextMatchModeSimilar.
+	Threshold float64 `json:"threshold,omitempty" yaml:"threshold,omitempty"` // New
 }
 
 type RuleTriggers struct {
-	AvatarMatch       []RuleTriggerAvatarMatch `json:"avatar_match" yaml:"avatar_match"`
+	AvatarMatch       []RuleTriggerAvatarMatch `json:"avatar_match" yaml:"avatar_match,omitempty"`
 	Mode              RuleTriggerMode          `json:"mode" yaml:"mode"`
-	UsernameTextMatch *RuleTriggerNameMatch    `json:"username_text_match" yaml:"username_text_match"` //nolint:tagliatelle
-	ChatMsgTextMatch  *RuleTriggerTextMatch    `json:"chatmsg_text_match" yaml:"chat_msg_text_match"`  //nolint:tagliatelle
+	UsernameTextMatch *RuleTriggerNameMatch    `json:"username_text_match" yaml:"username_text_match,omitempty"` //nolint:tagliatelle
+	ChatMsgTextMatch  *RuleTriggerTextMatch    `json:"chatmsg_text_match" yaml:"chat_msg_text_match,omitempty"`  //nolint:tagliatelle
+}
+
+// triggerCount returns the number of distinct trigger types that are defined.
+func (t RuleTriggers) triggerCount() int {
+	count := 0
+
+	if t.UsernameTextMatch != nil {
+		count++
+	}
+
+	if t.ChatMsgTextMatch != nil {
+		count++
+	}
+
+	if len(t.AvatarMatch) > 0 {
+		count++
+	}
+
+	return count
+}
+
+// attributes returns the combined attributes of all the defined triggers.
+func (t RuleTriggers) attributes() []string {
+	var attrs []string
+
+	if t.UsernameTextMatch != nil {
+		attrs = append(attrs, t.UsernameTextMatch.Attributes...)
+	}
+
+	if t.ChatMsgTextMatch != nil {
+		attrs = append(attrs, t.ChatMsgTextMatch.Attributes...)
+	}
+
+	if len(attrs) == 0 {
+		attrs = append(attrs, "trigger_multi")
+	}
+
+	return attrs
 }
 
 type RuleActions struct {
-	TransientMark []string                 `json:"transient_mark"`
-	AvatarMatch   []RuleTriggerAvatarMatch `json:"avatar_match"` // ?
-	Mark          []string                 `json:"mark"`
+	TransientMark []string                 `json:"transient_mark" yaml:"transient_mark,omitempty"`
+	AvatarMatch   []RuleTriggerAvatarMatch `json:"avatar_match" yaml:"avatar_match,omitempty"` // ?
+	Mark          []string                 `json:"mark" yaml:"mark,omitempty"`
 }
 
 type RuleDefinition struct {
-	Actions     RuleActions  `json:"actions,omitempty"`
-	Description string       `json:"description"`
-	Triggers    RuleTriggers `json:"triggers,omitempty"`
+	Actions     RuleActions  `json:"actions,omitempty" yaml:"actions,omitempty"`
+	Description string       `json:"description" yaml:"description"`
+	Triggers    RuleTriggers `json:"triggers,omitempty" yaml:"triggers"`
+	// Severity of a match against the rule, defaults to SeverityMedium
+	Severity Severity `json:"severity,omitempty" yaml:"severity,omitempty"`
+	// Weight overrides the default points of the severity added to the suspicion score when matched
+	Weight float64 `json:"weight,omitempty" yaml:"weight,omitempty"`
 }
 
 type PlayerListSchema struct {
-	BaseSchema
-	Players       []PlayerDefinition      `json:"players"`
+	BaseSchema    `yaml:",inline"`
+	Players       []PlayerDefinition      `json:"players" yaml:"players"`
 	matchersSteam []SteamIDMatcherHandler `yaml:"-"`
+	// steamIndex provides lookup of the matchers for a specific steam id within this list
+	steamIndex map[steamid.SteamID][]SteamIDMatcherHandler `yaml:"-"`
+	// aggregate is the engine wide index this list is registered with, if any
+	aggregate *steamIndex `yaml:"-"`
 }
 
 type PlayerLastSeen struct {
-	PlayerName string `json:"player_name,omitempty"`
-	Time       int64  `json:"time,omitempty"`
+	PlayerName string `json:"player_name,omitempty" yaml:"player_name,omitempty"`
+	Time       int64  `json:"time,omitempty" yaml:"time,omitempty"`
 }
 
 type PlayerDefinition struct {
-	Attributes []string        `json:"attributes"`
-	LastSeen   PlayerLastSeen  `json:"last_seen,omitempty"`
-	SteamID    steamid.SteamID `json:"steamid"` //nolint:tagliatelle
-	Proof      []string        `json:"proof,omitempty"`
-	Origin     string          `json:"origin,omitempty"`
+	Attributes []string        `json:"attributes" yaml:"attributes"`
+	LastSeen   PlayerLastSeen  `json:"last_seen,omitempty" yaml:"last_seen,omitempty"`
+	SteamID    steamid.SteamID `json:"steamid" yaml:"steamid"` //nolint:tagliatelle
+	Proof      []string        `json:"proof,omitempty" yaml:"proof,omitempty"`
+	Origin     string          `json:"origin,omitempty" yaml:"origin,omitempty"`
+	// Expires is the unix time after which the entry no longer matches. 0 never expires.
+	Expires int64 `json:"expires,omitempty" yaml:"expires,omitempty"`
+	// AttributeExpiry is the unix time after which each of the attributes no longer apply. Attributes without an
+	// expiry only expire along with the entry.
+	AttributeExpiry map[string]int64 `json:"attribute_expiry,omitempty" yaml:"attribute_expiry,omitempty"`
 }
@@ -0,0 +1,228 @@
+package rules
+
+import (
+	"fmt"
+	"time"
+)
+
+// Severity describes how confident a match is that the player should be acted upon.
+type Severity string
+
+const (
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+// MaxSuspicionScore is the upper bound of a players suspicion score.
+const MaxSuspicionScore = 100.0
+
+// Weight returns the default number of points a match of the severity adds to a players suspicion score. Unknown
+// severities are treated as SeverityMedium.
+func (s Severity) Weight() float64 {
+	switch s {
+	case SeverityLow:
+		return 10
+	case SeverityHigh:
+		return 50
+	case SeverityCritical:
+		return MaxSuspicionScore
+	case SeverityMedium:
+		fallthrough
+	default:
+		return 25
+	}
+}
+
+func (s Severity) rank() int {
+	switch s {
+	case SeverityLow:
+		return 1
+	case SeverityHigh:
+		return 3
+	case SeverityCritical:
+		return 4
+	case SeverityMedium:
+		fallthrough
+	default:
+		return 2
+	}
+}
+
+// SuspicionInput contains everything known about a player that contributes to their suspicion score.
+type SuspicionInput struct {
+	Matches          []MatchResult
+	VACBans          int
+	GameBans         int
+	CommunityBanned  bool
+	EconomyBanned    bool
+	Sourcebans       int
+	AccountCreatedOn time.Time
+}
+
+// SuspicionComponent is a single contributor to a players suspicion score.
+type SuspicionComponent struct {
+	Source      string  `json:"source"`
+	Description string  `json:"description"`
+	Points      float64 `json:"points"`
+}
+
+// SuspicionScore is the combined score of everything known about a player, from 0 to MaxSuspicionScore.
+type SuspicionScore struct {
+	Total     float64              `json:"total"`
+	Breakdown []SuspicionComponent `json:"breakdown"`
+}
+
+func (s *SuspicionScore) add(source string, description string, points float64) {
+	if points <= 0 {
+		return
+	}
+
+	s.Breakdown = append(s.Breakdown, SuspicionComponent{Source: source, Description: description, Points: points})
+	s.Total += points
+}
+
+const (
+	vacBanPoints           = 30
+	vacBanAdditionalPoints = 10
+	vacBanMaxPoints        = 50
+	gameBanPoints          = 15
+	gameBanMaxPoints       = 30
+	communityBanPoints     = 10
+	economyBanPoints       = 10
+	sourcebanPoints        = 5
+	sourcebanMaxPoints     = 25
+	newAccountPoints       = 20
+	newAccountAge          = time.Hour * 24 * 30
+	youngAccountPoints     = 10
+	youngAccountAge        = time.Hour * 24 * 180
+)
+
+// Suspicion combines all the known information about a player into a single score with a breakdown of how each
+// value contributed to it. Matches contribute their weight multiplied by the weight of the list they originated
+// from, so a single weak name match counts far less than an entry on a confirmed cheater list.
+func (e *Engine) Suspicion(input SuspicionInput) SuspicionScore {
+	score := SuspicionScore{Breakdown: []SuspicionComponent{}}
+
+	e.RLock()
+	for _, match := range input.Matches {
+		weight := match.Weight
+		if weight <= 0 {
+			weight = match.Severity.Weight()
+		}
+
+		description := match.Description
+		if description == "" {
+			description = match.Pattern
+		}
+
+		score.add("match", fmt.Sprintf("%s: %s", match.Origin, description), weight*e.listWeight(match.Origin))
+	}
+	e.RUnlock()
+
+	if input.VACBans > 0 {
+		score.add("vac_bans", fmt.Sprintf("%d VAC ban(s)", input.VACBans),
+			min(vacBanPoints+float64(input.VACBans-1)*vacBanAdditionalPoints, vacBanMaxPoints))
+	}
+
+	if input.GameBans > 0 {
+		score.add("game_bans", fmt.Sprintf("%d game ban(s)", input.GameBans),
+			min(float64(input.GameBans)*gameBanPoints, gameBanMaxPoints))
+	}
+
+	if input.CommunityBanned {
+		score.add("community_ban", "Community banned", communityBanPoints)
+	}
+
+	if input.EconomyBanned {
+		score.add("economy_ban", "Economy banned", economyBanPoints)
+	}
+
+	if input.Sourcebans > 0 {
+		score.add("sourcebans", fmt.Sprintf("%d sourceban(s)", input.Sourcebans),
+			min(float64(input.Sourcebans)*sourcebanPoints, sourcebanMaxPoints))
+	}
+
+	// Accounts with private profiles have no known creation date and do not contribute
+	if !input.AccountCreatedOn.IsZero() {
+		age := time.Since(input.AccountCreatedOn)
+
+		switch {
+		case age < newAccountAge:
+			score.add("account_age", "Account created less than 30 days ago", newAccountPoints)
+		case age < youngAccountAge:
+			score.add("account_age", "Account created less than 180 days ago", youngAccountPoints)
+		}
+	}
+
+	score.Total = min(score.Total, MaxSuspicionScore)
+
+	return score
+}
+
+// withSeverity fills in the severity and weight of the match when they were not defined by the rule that created
+// it. The severity of the list is preferred over the fallback.
+func (b BaseSchema) withSeverity(result MatchResult, fallback Severity) MatchResult {
+	if result.Severity == "" {
+		result.Severity = b.Severity
+	}
+
+	if result.Severity == "" {
+		result.Severity = fallback
+	}
+
+	if result.Weight <= 0 {
+		result.Weight = result.Severity.Weight()
+	}
+
+	return result
+}
+
+// SetListScoring updates the weight and severity of the lists matching the title or url.
+func (e *Engine) SetListScoring(titleOrURL string, weight float64, severity Severity) error {
+	e.Lock()
+	defer e.Unlock()
+
+	found := false
+
+	for _, list := range e.playerLists {
+		if list.matches(titleOrURL) {
+			list.Weight = weight
+			list.Severity = severity
+			found = true
+		}
+	}
+
+	for _, list := range e.rulesLists {
+		if list.matches(titleOrURL) {
+			list.Weight = weight
+			list.Severity = severity
+			found = true
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("%w: %s", ErrUnknownList, titleOrURL)
+	}
+
+	return nil
+}
+
+// listWeight returns the weight of the list with the title. Unknown lists, and those without a weight set, have a
+// weight of 1. The caller must hold the read lock.
+func (e *Engine) listWeight(title string) float64 {
+	for _, list := range e.playerLists {
+		if list.FileInfo.Title == title && list.Weight > 0 {
+			return list.Weight
+		}
+	}
+
+	for _, list := range e.rulesLists {
+		if list.FileInfo.Title == title && list.Weight > 0 {
+			return list.Weight
+		}
+	}
+
+	return 1
+}
@@ -0,0 +1,94 @@
+package rules_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/bd/rules"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuspicionScore(t *testing.T) {
+	const cheaterListTitle = "cheaters"
+
+	var (
+		engine    = rules.New()
+		cheaterID = steamid.New(76561197961279983)
+	)
+
+	_, errRules := engine.ImportRules(&rules.RuleSchema{
+		BaseSchema: rules.BaseSchema{FileInfo: rules.FileInfo{Title: customListTitle}},
+		Rules: []rules.RuleDefinition{
+			{
+				Description: "weak name",
+				Severity:    rules.SeverityLow,
+				Triggers: rules.RuleTriggers{
+					UsernameTextMatch: &rules.RuleTriggerNameMatch{Mode: rules.TextMatchModeContains, Patterns: []string{"bot"}},
+				},
+			},
+			{
+				Description: "weighted name",
+				Weight:      42,
+				Triggers: rules.RuleTriggers{
+					UsernameTextMatch: &rules.RuleTriggerNameMatch{Mode: rules.TextMatchModeEqual, Patterns: []string{"weighted"}},
+				},
+			},
+		},
+	})
+	require.NoError(t, errRules)
+
+	_, errPlayers := engine.ImportPlayers(&rules.PlayerListSchema{
+		BaseSchema: rules.BaseSchema{FileInfo: rules.FileInfo{Title: cheaterListTitle}},
+		Players:    []rules.PlayerDefinition{{SteamID: cheaterID, Attributes: []string{"cheater"}}},
+	})
+	require.NoError(t, errPlayers)
+
+	weak := engine.MatchName("a bot name")
+	require.Len(t, weak, 1)
+	require.Equal(t, rules.SeverityLow, weak[0].Severity)
+	require.InDelta(t, rules.SeverityLow.Weight(), weak[0].Weight, 0.001)
+
+	weighted := engine.MatchName("weighted")
+	require.Len(t, weighted, 1)
+	require.Equal(t, rules.SeverityMedium, weighted[0].Severity)
+	require.InDelta(t, 42, weighted[0].Weight, 0.001)
+
+	cheater := engine.MatchSteam(cheaterID)
+	require.Len(t, cheater, 1)
+	require.Equal(t, rules.SeverityCritical, cheater[0].Severity)
+
+	weakScore := engine.Suspicion(rules.SuspicionInput{Matches: weak})
+	cheaterScore := engine.Suspicion(rules.SuspicionInput{Matches: cheater})
+	require.Less(t, weakScore.Total, cheaterScore.Total)
+	require.InDelta(t, rules.MaxSuspicionScore, cheaterScore.Total, 0.001)
+	require.Len(t, weakScore.Breakdown, 1)
+
+	// List weights scale the points of the matches from the list
+	require.NoError(t, engine.SetListScoring(customListTitle, 0.5, ""))
+	require.InDelta(t, weakScore.Total/2, engine.Suspicion(rules.SuspicionInput{Matches: weak}).Total, 0.001)
+	require.ErrorIs(t, engine.SetListScoring("unknown", 1, ""), rules.ErrUnknownList)
+
+	// List severities apply to matches without a severity set by their rule
+	require.NoError(t, engine.SetListScoring(customListTitle, 1, rules.SeverityHigh))
+	require.Equal(t, rules.SeverityHigh, engine.MatchName("weighted")[0].Severity)
+	require.InDelta(t, 42, engine.MatchName("weighted")[0].Weight, 0.001)
+	require.Equal(t, rules.SeverityLow, engine.MatchName("a bot name")[0].Severity)
+
+	require.NoError(t, engine.SetListScoring(cheaterListTitle, 1, rules.SeverityLow))
+	require.Equal(t, rules.SeverityLow, engine.MatchSteam(cheaterID)[0].Severity)
+
+	combined := engine.Suspicion(rules.SuspicionInput{
+		VACBans:          2,
+		GameBans:         1,
+		CommunityBanned:  true,
+		Sourcebans:       3,
+		AccountCreatedOn: time.Now().Add(-time.Hour * 24),
+	})
+	require.Len(t, combined.Breakdown, 5)
+	require.InDelta(t, rules.MaxSuspicionScore, combined.Total, 0.001)
+
+	private := engine.Suspicion(rules.SuspicionInput{})
+	require.Empty(t, private.Breakdown)
+	require.Zero(t, private.Total)
+}
@@ -0,0 +1,50 @@
+package rules
+
+// levenshtein calculates the minimum number of single character insertions, deletions or substitutions
+// required to change a into b.
+func levenshtein(a []rune, b []rune) int {
+	if len(a) == 0 {
+		return len(b)
+	}
+
+	if len(b) == 0 {
+		return len(a)
+	}
+
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+
+	for idx := range previous {
+		previous[idx] = idx
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+// Similarity returns a 0.0-1.0 score of how similar the two strings are based on their edit distance relative
+// to the length of the longest string. Identical strings return 1.0.
+func Similarity(a string, b string) float64 {
+	runesA, runesB := []rune(a), []rune(b)
+
+	longest := max(len(runesA), len(runesB))
+	if longest == 0 {
+		return 0
+	}
+
+	return 1 - float64(levenshtein(runesA, runesB))/float64(longest)
+}
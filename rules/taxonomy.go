@@ -0,0 +1,323 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var ErrInvalidTaxonomy = errors.New("invalid attribute taxonomy")
+
+// AttributeSeparator separates the levels of hierarchical attributes, eg: cheater/aimbot is a child of cheater.
+const AttributeSeparator = "/"
+
+// AttributeDefinition describes a canonical attribute, the aliases other lists use for it and how it should
+// be displayed.
+type AttributeDefinition struct {
+	// Name is the canonical name of the attribute, children are defined using AttributeSeparator.
+	Name string `json:"name" yaml:"name"`
+	// Aliases are equivalent tags which are mapped onto the canonical name.
+	Aliases     []string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	DisplayName string   `json:"display_name,omitempty" yaml:"display_name,omitempty"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	// Color is the hex colour used when displaying the attribute.
+	Color    string   `json:"color,omitempty" yaml:"color,omitempty"`
+	Severity Severity `json:"severity,omitempty" yaml:"severity,omitempty"`
+	// Kick indicates the attribute is one that should be kicked by default.
+	Kick bool `json:"kick" yaml:"kick"`
+}
+
+// DefaultAttributes returns the built-in attribute taxonomy.
+func DefaultAttributes() []AttributeDefinition {
+	return []AttributeDefinition{
+		{
+			Name:        "cheater",
+			Aliases:     []string{"cheat", "hacker"},
+			DisplayName: "Cheater",
+			Description: "Uses cheats such as aimbots, triggerbots or wallhacks",
+			Color:       "#d32f2f",
+			Severity:    SeverityCritical,
+			Kick:        true,
+		},
+		{
+			Name:        "bot",
+			Aliases:     []string{"bots"},
+			DisplayName: "Bot",
+			Description: "Automated bot account",
+			Color:       "#7b1fa2",
+			Severity:    SeverityCritical,
+			Kick:        true,
+		},
+		{
+			Name:        "exploiter",
+			Aliases:     []string{"exploit"},
+			DisplayName: "Exploiter",
+			Description: "Abuses game exploits",
+			Color:       "#f57c00",
+			Severity:    SeverityHigh,
+		},
+		{
+			Name:        "racist",
+			DisplayName: "Racist",
+			Description: "Uses racist language in names or chat",
+			Color:       "#5d4037",
+			Severity:    SeverityMedium,
+		},
+		{
+			Name:        "suspicious",
+			DisplayName: "Suspicious",
+			Description: "Suspected of cheating but not confirmed",
+			Color:       "#fbc02d",
+			Severity:    SeverityLow,
+		},
+		{
+			Name:        "impersonator",
+			DisplayName: "Impersonator",
+			Description: "Copies the name of another player in the same game",
+			Color:       "#c2185b",
+			Severity:    SeverityHigh,
+		},
+		{
+			Name:        "spammer",
+			Aliases:     []string{"spam", "chat_spam"},
+			DisplayName: "Spammer",
+			Description: "Floods the chat with repeated or excessive messages",
+			Color:       "#689f38",
+			Severity:    SeverityLow,
+		},
+		{
+			Name:        "trigger_name",
+			DisplayName: "Name Rule",
+			Description: "Matched a name rule",
+			Color:       "#1976d2",
+			Severity:    SeverityMedium,
+			Kick:        true,
+		},
+		{
+			Name:        "trigger_msg",
+			DisplayName: "Message Rule",
+			Description: "Matched a chat message rule",
+			Color:       "#0288d1",
+			Severity:    SeverityMedium,
+			Kick:        true,
+		},
+	}
+}
+
+// Taxonomy maps the free-form attributes used by lists onto canonical attributes. Aliases of a parent attribute
+// also apply to its children, so if "hacker" is an alias of "cheater" then "hacker/aimbot" becomes "cheater/aimbot".
+//
+// A Taxonomy is immutable once created and safe for concurrent use.
+type Taxonomy struct {
+	definitions []AttributeDefinition
+	// names maps each canonical name to the index of its definition
+	names map[string]int
+	// aliases maps each alias to its canonical name
+	aliases map[string]string
+}
+
+// NewTaxonomy validates the definitions and creates a taxonomy from them. Every name and alias must be unique.
+func NewTaxonomy(definitions []AttributeDefinition) (*Taxonomy, error) {
+	seen := map[string]bool{}
+
+	for _, definition := range definitions {
+		name := normaliseAttribute(definition.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: attribute name cannot be empty", ErrInvalidTaxonomy)
+		}
+
+		for _, tag := range append([]string{name}, definition.Aliases...) {
+			tag = normaliseAttribute(tag)
+			if seen[tag] {
+				return nil, fmt.Errorf("%w: duplicate attribute or alias: %s", ErrInvalidTaxonomy, tag)
+			}
+
+			seen[tag] = true
+		}
+	}
+
+	return newTaxonomy(definitions), nil
+}
+
+func newTaxonomy(definitions []AttributeDefinition) *Taxonomy {
+	taxonomy := &Taxonomy{
+		definitions: slices.Clone(definitions),
+		names:       map[string]int{},
+		aliases:     map[string]string{},
+	}
+
+	for index, definition := range taxonomy.definitions {
+		name := normaliseAttribute(definition.Name)
+		taxonomy.names[name] = index
+
+		for _, alias := range definition.Aliases {
+			taxonomy.aliases[normaliseAttribute(alias)] = name
+		}
+	}
+
+	return taxonomy
+}
+
+func normaliseAttribute(attr string) string {
+	return strings.ToLower(strings.TrimSpace(attr))
+}
+
+// Canonical returns the canonical form of the attribute. Unknown attributes are returned lower-cased.
+func (t *Taxonomy) Canonical(attr string) string {
+	attr = normaliseAttribute(attr)
+
+	if canonical, found := t.aliases[attr]; found {
+		return canonical
+	}
+
+	if root, child, found := strings.Cut(attr, AttributeSeparator); found {
+		if canonical, rootFound := t.aliases[root]; rootFound {
+			return canonical + AttributeSeparator + child
+		}
+	}
+
+	return attr
+}
+
+// CanonicalAll returns the unique canonical forms of the attributes, retaining their order.
+func (t *Taxonomy) CanonicalAll(attrs []string) []string {
+	if attrs == nil {
+		return nil
+	}
+
+	canonical := make([]string, 0, len(attrs))
+
+	for _, attr := range attrs {
+		attr = t.Canonical(attr)
+		if !slices.Contains(canonical, attr) {
+			canonical = append(canonical, attr)
+		}
+	}
+
+	return canonical
+}
+
+// Expand returns the canonical forms of the attributes along with all of their aliases, and the aliases of any of
+// their children, for use when matching against attributes that have not been canonicalised. Children are also
+// expanded using the aliases of their parents, eg: cheater/aimbot includes hacker/aimbot.
+func (t *Taxonomy) Expand(attrs []string) []string {
+	var (
+		canonical = t.CanonicalAll(attrs)
+		expanded  = slices.Clone(canonical)
+	)
+
+	for alias, aliasCanonical := range t.aliases {
+		for _, attr := range canonical {
+			switch {
+			case t.Covers(attr, aliasCanonical):
+				expanded = append(expanded, alias)
+			case t.Covers(aliasCanonical, attr):
+				expanded = append(expanded, alias+attr[len(aliasCanonical):])
+			}
+		}
+	}
+
+	return expanded
+}
+
+// Covers returns true if the attribute is the parent attribute, or one of its children.
+func (t *Taxonomy) Covers(parent string, attr string) bool {
+	return attributeCovers(t.Canonical(parent), t.Canonical(attr))
+}
+
+// Definition returns the definition of the attribute. Children without their own definition inherit the
+// definition of their closest parent.
+func (t *Taxonomy) Definition(attr string) (AttributeDefinition, bool) {
+	attr = t.Canonical(attr)
+
+	for {
+		if index, found := t.names[attr]; found {
+			return t.definitions[index], true
+		}
+
+		parentEnd := strings.LastIndex(attr, AttributeSeparator)
+		if parentEnd < 0 {
+			return AttributeDefinition{}, false
+		}
+
+		attr = attr[:parentEnd]
+	}
+}
+
+// Severity returns the most severe of the severities defined for the attributes. Attributes inherit the severity of
+// their parents, those without any defined severity are treated as SeverityMedium.
+func (t *Taxonomy) Severity(attrs []string) Severity {
+	if len(attrs) == 0 {
+		return SeverityMedium
+	}
+
+	severity := SeverityLow
+
+	for _, attr := range attrs {
+		if attrSeverity := t.attributeSeverity(attr); attrSeverity.rank() > severity.rank() {
+			severity = attrSeverity
+		}
+	}
+
+	return severity
+}
+
+// attributeSeverity returns the severity of the closest definition of the attribute, or its parents, which defines
+// one.
+func (t *Taxonomy) attributeSeverity(attr string) Severity {
+	for attr = t.Canonical(attr); attr != ""; {
+		definition, found := t.Definition(attr)
+		if !found {
+			break
+		}
+
+		if definition.Severity != "" {
+			return definition.Severity
+		}
+
+		name := normaliseAttribute(definition.Name)
+
+		parentEnd := strings.LastIndex(name, AttributeSeparator)
+		if parentEnd < 0 {
+			break
+		}
+
+		attr = name[:parentEnd]
+	}
+
+	return SeverityMedium
+}
+
+// KickAttributes returns the canonical names of the attributes that are kicked by default.
+func KickAttributes(definitions []AttributeDefinition) []string {
+	var kick []string
+
+	for _, definition := range definitions {
+		if definition.Kick {
+			kick = append(kick, normaliseAttribute(definition.Name))
+		}
+	}
+
+	return kick
+}
+
+// canonicalResult rewrites the attributes of the match result into their canonical forms.
+func (t *Taxonomy) canonicalResult(result MatchResult) MatchResult {
+	result.Attributes = t.CanonicalAll(result.Attributes)
+	result.Mark = t.CanonicalAll(result.Mark)
+	result.TransientMark = t.CanonicalAll(result.TransientMark)
+
+	return result
+}
+
+// attributeCovers returns true if the attribute is the same as the parent or is one of its children, ignoring case.
+func attributeCovers(parent string, attr string) bool {
+	if strings.EqualFold(parent, attr) {
+		return true
+	}
+
+	return len(attr) > len(parent) &&
+		strings.EqualFold(attr[:len(parent)], parent) &&
+		strings.HasPrefix(attr[len(parent):], AttributeSeparator)
+}
@@ -0,0 +1,87 @@
+package rules_test
+
+import (
+	"testing"
+
+	"github.com/leighmacdonald/bd/rules"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaxonomy(t *testing.T) {
+	taxonomy, errTaxonomy := rules.NewTaxonomy([]rules.AttributeDefinition{
+		{Name: "cheater", Aliases: []string{"hacker"}, Severity: rules.SeverityCritical, Kick: true},
+		{Name: "cheater/aimbot", Aliases: []string{"aimbot"}},
+		{Name: "suspicious", Severity: rules.SeverityLow},
+	})
+	require.NoError(t, errTaxonomy)
+
+	require.Equal(t, "cheater", taxonomy.Canonical("Hacker"))
+	require.Equal(t, "cheater/aimbot", taxonomy.Canonical("aimbot"))
+	require.Equal(t, "cheater/wallhack", taxonomy.Canonical("hacker/wallhack"))
+	require.Equal(t, "racist", taxonomy.Canonical("Racist"))
+	require.Equal(t, []string{"cheater", "cheater/aimbot"}, taxonomy.CanonicalAll([]string{"cheater", "HACKER", "aimbot"}))
+
+	require.True(t, taxonomy.Covers("cheater", "hacker/triggerbot"))
+	require.True(t, taxonomy.Covers("hacker", "cheater"))
+	require.False(t, taxonomy.Covers("cheater/aimbot", "cheater"))
+	require.False(t, taxonomy.Covers("cheat", "cheater"))
+
+	// Children inherit the definition of their parent
+	definition, found := taxonomy.Definition("hacker/wallhack")
+	require.True(t, found)
+	require.Equal(t, "cheater", definition.Name)
+
+	require.Equal(t, rules.SeverityCritical, taxonomy.Severity([]string{"suspicious", "aimbot"}))
+	require.Equal(t, rules.SeverityMedium, taxonomy.Severity([]string{"suspicious", "unknown"}))
+	require.Equal(t, []string{"cheater", "bot", "trigger_name", "trigger_msg"}, rules.KickAttributes(rules.DefaultAttributes()))
+	require.Equal(t, []string{"cheater"}, rules.KickAttributes([]rules.AttributeDefinition{
+		{Name: "Cheater", Kick: true},
+		{Name: "suspicious"},
+	}))
+
+	_, errDuplicate := rules.NewTaxonomy([]rules.AttributeDefinition{
+		{Name: "cheater"},
+		{Name: "hacker", Aliases: []string{"Cheater"}},
+	})
+	require.ErrorIs(t, errDuplicate, rules.ErrInvalidTaxonomy)
+}
+
+func TestEngineTaxonomy(t *testing.T) {
+	engine := rules.New()
+	require.NoError(t, engine.SetTaxonomy([]rules.AttributeDefinition{
+		{Name: "cheater", Aliases: []string{"hacker"}, Severity: rules.SeverityCritical},
+		{Name: "cheater/aimbot", Aliases: []string{"aimbot"}},
+		{Name: "suspicious", Severity: rules.SeverityLow},
+	}))
+
+	var (
+		aimbotSID = steamid.New(76561197970669109)
+		suspectID = steamid.New(76561197992870439)
+		aliasSID  = steamid.New(76561197960287930)
+	)
+
+	_, errImport := engine.ImportPlayers(&rules.PlayerListSchema{
+		BaseSchema: rules.BaseSchema{FileInfo: rules.FileInfo{Title: customListTitle}},
+		Players: []rules.PlayerDefinition{
+			{SteamID: aimbotSID, Attributes: []string{"Hacker/aimbot"}, LastSeen: rules.PlayerLastSeen{Time: 2}},
+			{SteamID: suspectID, Attributes: []string{"suspicious", "cheater"}, LastSeen: rules.PlayerLastSeen{Time: 1}},
+			{SteamID: aliasSID, Attributes: []string{"Aimbot"}, LastSeen: rules.PlayerLastSeen{Time: 3}},
+		},
+	})
+	require.NoError(t, errImport)
+
+	matches := engine.MatchSteam(aimbotSID)
+	require.Len(t, matches, 1)
+	require.Equal(t, []string{"cheater/aimbot"}, matches[0].Attributes)
+	require.Equal(t, rules.SeverityCritical, matches[0].Severity)
+	require.True(t, matches[0].HasAttr("cheater"))
+	require.False(t, matches[0].HasAttr("cheater/wallhack"))
+
+	require.ElementsMatch(t, []string{"cheater/aimbot", "suspicious", "cheater"}, engine.UniqueTags())
+
+	// Aliases used by the lists are found when searching by the canonical tag
+	require.Equal(t, steamid.Collection{aliasSID, aimbotSID, suspectID}, engine.FindNewestEntries(10, []string{"cheater"}))
+	require.Equal(t, steamid.Collection{aliasSID, aimbotSID}, engine.FindNewestEntries(10, []string{"cheater/aimbot"}))
+	require.Equal(t, steamid.Collection{suspectID}, engine.FindNewestEntries(10, []string{"suspicious"}))
+}
@@ -0,0 +1,154 @@
+package rules
+
+import (
+	"github.com/leighmacdonald/steamid/v4/steamid"
+)
+
+// RuleTestOrigin is used as the origin of any matches generated while testing a rule.
+const RuleTestOrigin = "rule_test"
+
+// RuleTestPlayer contains all the known values for a single player that a rule is tested against.
+type RuleTestPlayer struct {
+	SteamID    steamid.SteamID `json:"steam_id"`
+	Names      []string        `json:"names"`
+	Messages   []string        `json:"messages"`
+	AvatarHash string          `json:"avatar_hash"`
+}
+
+// RuleTestSamples defines the sample inputs that a rule is tested against. Names, messages and avatar hashes are
+// evaluated individually while players are evaluated using all of their values at once, which is required for
+// testing rules using RuleTriggerModeMatchAll.
+type RuleTestSamples struct {
+	Names        []string         `json:"names"`
+	Messages     []string         `json:"messages"`
+	AvatarHashes []string         `json:"avatar_hashes"`
+	Players      []RuleTestPlayer `json:"players"`
+}
+
+// RuleTestMatch is the result of testing a rule against a single input value.
+type RuleTestMatch struct {
+	Input   string        `json:"input"`
+	Matched bool          `json:"matched"`
+	Matches []MatchResult `json:"matches"`
+}
+
+// RuleTestResult contains the results for each of the sample inputs, in the same order they were provided.
+type RuleTestResult struct {
+	Names        []RuleTestMatch `json:"names"`
+	Messages     []RuleTestMatch `json:"messages"`
+	AvatarHashes []RuleTestMatch `json:"avatar_hashes"`
+	Players      []RuleTestMatch `json:"players"`
+}
+
+// RuleReplayResult summarises the results of testing a rule against a set of historical player data.
+type RuleReplayResult struct {
+	Total   int             `json:"total"`
+	Flagged int             `json:"flagged"`
+	Matches []RuleTestMatch `json:"matches"`
+}
+
+// newRuleTestSchema creates a standalone list containing only the rule, so it can be evaluated without being
+// registered with the engine.
+func newRuleTestSchema(rule RuleDefinition) (*RuleSchema, error) {
+	if errValidate := validateRule(rule); errValidate != nil {
+		return nil, errValidate
+	}
+
+	list := &RuleSchema{BaseSchema: BaseSchema{FileInfo: FileInfo{Title: RuleTestOrigin}}}
+
+	if _, errRegister := list.registerRule(rule); errRegister != nil {
+		return nil, errRegister
+	}
+
+	return list, nil
+}
+
+// TestRule evaluates a candidate rule against the provided samples without registering it. The attributes of the
+// matches are canonicalised using the current taxonomy, the same as they would be once the rule is loaded.
+func (e *Engine) TestRule(rule RuleDefinition, samples RuleTestSamples) (RuleTestResult, error) {
+	list, errList := newRuleTestSchema(rule)
+	if errList != nil {
+		return RuleTestResult{}, errList
+	}
+
+	e.RLock()
+	defer e.RUnlock()
+
+	result := RuleTestResult{
+		Names:        make([]RuleTestMatch, len(samples.Names)),
+		Messages:     make([]RuleTestMatch, len(samples.Messages)),
+		AvatarHashes: make([]RuleTestMatch, len(samples.AvatarHashes)),
+		Players:      make([]RuleTestMatch, len(samples.Players)),
+	}
+
+	for idx, name := range samples.Names {
+		match, found := list.matchTextType(name, TextMatchTypeName)
+		result.Names[idx] = newRuleTestMatch(name, found, e.taxonomy.canonicalResult(match))
+	}
+
+	for idx, message := range samples.Messages {
+		match, found := list.matchTextType(message, TextMatchTypeMessage)
+		result.Messages[idx] = newRuleTestMatch(message, found, e.taxonomy.canonicalResult(match))
+	}
+
+	for idx, hash := range samples.AvatarHashes {
+		// Samples may be either type of hash, matchers ignore hashes in the format of the other type
+		match, found := list.matchAvatarHash(hash, hash)
+		result.AvatarHashes[idx] = newRuleTestMatch(hash, found, e.taxonomy.canonicalResult(match))
+	}
+
+	for idx, player := range samples.Players {
+		result.Players[idx] = e.testPlayer(list, player)
+	}
+
+	return result, nil
+}
+
+// ReplayRule evaluates a candidate rule against historical player data without registering it. Only the players
+// that would have been flagged are included in the matches.
+func (e *Engine) ReplayRule(rule RuleDefinition, players []RuleTestPlayer) (RuleReplayResult, error) {
+	list, errList := newRuleTestSchema(rule)
+	if errList != nil {
+		return RuleReplayResult{}, errList
+	}
+
+	e.RLock()
+	defer e.RUnlock()
+
+	result := RuleReplayResult{Total: len(players), Matches: []RuleTestMatch{}}
+
+	for _, player := range players {
+		if match := e.testPlayer(list, player); match.Matched {
+			result.Flagged++
+			result.Matches = append(result.Matches, match)
+		}
+	}
+
+	return result, nil
+}
+
+// testPlayer evaluates the player against the list once for each of their known names, stopping at the first name
+// which matches. The caller must hold the read lock.
+func (e *Engine) testPlayer(list *RuleSchema, player RuleTestPlayer) RuleTestMatch {
+	names := player.Names
+	if len(names) == 0 {
+		names = []string{""}
+	}
+
+	for _, name := range names {
+		results := list.matchPlayer(PlayerInput{Name: name, Messages: player.Messages, AvatarHash: player.AvatarHash})
+		if len(results) > 0 {
+			return RuleTestMatch{Input: player.SteamID.String(), Matched: true, Matches: e.canonicalResults(results)}
+		}
+	}
+
+	return RuleTestMatch{Input: player.SteamID.String(), Matches: []MatchResult{}}
+}
+
+func newRuleTestMatch(input string, found bool, match MatchResult) RuleTestMatch {
+	if !found {
+		return RuleTestMatch{Input: input, Matches: []MatchResult{}}
+	}
+
+	return RuleTestMatch{Input: input, Matched: true, Matches: []MatchResult{match}}
+}
@@ -0,0 +1,73 @@
+package rules_test
+
+import (
+	"testing"
+
+	"github.com/leighmacdonald/bd/rules"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleTester(t *testing.T) {
+	engine := rules.New()
+	rule := rules.RuleDefinition{
+		Description: "omega bots",
+		Triggers: rules.RuleTriggers{
+			UsernameTextMatch: &rules.RuleTriggerNameMatch{Mode: rules.TextMatchModeContains, Patterns: []string{"omegatronic"}},
+			ChatMsgTextMatch:  &rules.RuleTriggerTextMatch{Mode: rules.TextMatchModeWord, Patterns: []string{"discord.gg"}},
+		},
+	}
+
+	result, errTest := engine.TestRule(rule, rules.RuleTestSamples{
+		Names:    []string{"OMEGATRONIC1", "Uncle Dane"},
+		Messages: []string{"gg", "join discord.gg now"},
+	})
+	require.NoError(t, errTest)
+	require.True(t, result.Names[0].Matched)
+	require.Equal(t, rules.RuleTestOrigin, result.Names[0].Matches[0].Origin)
+	require.Equal(t, "omegatronic", result.Names[0].Matches[0].Pattern)
+	require.False(t, result.Names[1].Matched)
+	require.Empty(t, result.Names[1].Matches)
+	require.False(t, result.Messages[0].Matched)
+	require.True(t, result.Messages[1].Matched)
+	require.Empty(t, result.AvatarHashes)
+
+	require.Nil(t, engine.MatchName("OMEGATRONIC1"), "Tested rules should not be registered")
+	require.Empty(t, engine.Rules())
+
+	_, errInvalid := engine.TestRule(rules.RuleDefinition{}, rules.RuleTestSamples{})
+	require.ErrorIs(t, errInvalid, rules.ErrInvalidRule)
+}
+
+func TestRuleTesterPlayers(t *testing.T) {
+	var (
+		engine  = rules.New()
+		botID   = steamid.New(76561197961279983)
+		humanID = steamid.New(76561197960265730)
+		rule    = rules.RuleDefinition{
+			Description: "omega bots",
+			Triggers: rules.RuleTriggers{
+				Mode:              rules.RuleTriggerModeMatchAll,
+				UsernameTextMatch: &rules.RuleTriggerNameMatch{Mode: rules.TextMatchModeContains, Patterns: []string{"omegatronic"}},
+				ChatMsgTextMatch:  &rules.RuleTriggerTextMatch{Mode: rules.TextMatchModeWord, Patterns: []string{"discord.gg"}},
+			},
+		}
+		players = []rules.RuleTestPlayer{
+			{SteamID: botID, Names: []string{"bot", "OMEGATRONIC1"}, Messages: []string{"gg", "discord.gg"}},
+			{SteamID: humanID, Names: []string{"OMEGATRONIC fan"}, Messages: []string{"gg"}},
+		}
+	)
+
+	result, errTest := engine.TestRule(rule, rules.RuleTestSamples{Names: []string{"OMEGATRONIC1"}, Players: players})
+	require.NoError(t, errTest)
+	require.False(t, result.Names[0].Matched, "Match all rules require all triggers")
+	require.True(t, result.Players[0].Matched)
+	require.Equal(t, botID.String(), result.Players[0].Input)
+	require.False(t, result.Players[1].Matched)
+
+	replay, errReplay := engine.ReplayRule(rule, players)
+	require.NoError(t, errReplay)
+	require.Equal(t, 2, replay.Total)
+	require.Equal(t, 1, replay.Flagged)
+	require.Equal(t, botID.String(), replay.Matches[0].Input)
+}
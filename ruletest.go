@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/leighmacdonald/bd/rules"
+	"github.com/leighmacdonald/bd/store"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+)
+
+// ruleTestPlayer loads the stored names, messages and avatar hash of the player for use when testing rules.
+func ruleTestPlayer(ctx context.Context, db store.Querier, steamID steamid.SteamID) (rules.RuleTestPlayer, error) {
+	testPlayer := rules.RuleTestPlayer{SteamID: steamID}
+
+	player, errPlayer := db.Player(ctx, steamID.Int64())
+	if errPlayer != nil && !errors.Is(errPlayer, sql.ErrNoRows) {
+		return testPlayer, errors.Join(errPlayer, errGetPlayer)
+	}
+
+	testPlayer.AvatarHash = player.AvatarHash
+
+	if player.Personaname != "" {
+		testPlayer.Names = append(testPlayer.Names, player.Personaname)
+	}
+
+	names, errNames := db.UserNames(ctx, steamID.Int64())
+	if errNames != nil {
+		return testPlayer, errors.Join(errNames, errGetNames)
+	}
+
+	for _, name := range names {
+		testPlayer.Names = append(testPlayer.Names, name.Name)
+	}
+
+	messages, errMessages := db.Messages(ctx, steamID.Int64())
+	if errMessages != nil {
+		return testPlayer, errors.Join(errMessages, errGetMessages)
+	}
+
+	for _, message := range messages {
+		testPlayer.Messages = append(testPlayer.Messages, message.Message)
+	}
+
+	return testPlayer, nil
+}
+
+// ruleReplayPlayers loads the full history of stored names and messages, grouped by player.
+func ruleReplayPlayers(ctx context.Context, db store.Querier) ([]rules.RuleTestPlayer, error) {
+	var (
+		order   []steamid.SteamID
+		players = map[steamid.SteamID]*rules.RuleTestPlayer{}
+	)
+
+	getPlayer := func(sid64 int64) *rules.RuleTestPlayer {
+		steamID := steamid.New(sid64)
+
+		player, found := players[steamID]
+		if !found {
+			player = &rules.RuleTestPlayer{SteamID: steamID}
+			players[steamID] = player
+			order = append(order, steamID)
+		}
+
+		return player
+	}
+
+	names, errNames := db.UserNamesAll(ctx)
+	if errNames != nil {
+		return nil, errors.Join(errNames, errGetNames)
+	}
+
+	for _, name := range names {
+		player := getPlayer(name.SteamID)
+		player.Names = append(player.Names, name.Name)
+	}
+
+	messages, errMessages := db.MessagesAll(ctx)
+	if errMessages != nil {
+		return nil, errors.Join(errMessages, errGetMessages)
+	}
+
+	for _, message := range messages {
+		player := getPlayer(message.SteamID)
+		player.Messages = append(player.Messages, message.Message)
+	}
+
+	results := make([]rules.RuleTestPlayer, len(order))
+	for idx, steamID := range order {
+		results[idx] = *players[steamID]
+	}
+
+	return results, nil
+}
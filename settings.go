@@ -1,17 +1,20 @@
 package main
 
 import (
+	"bytes"
 	"crypto/rand"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/kirsle/configdir"
 	"github.com/leighmacdonald/bd/platform"
@@ -42,6 +45,10 @@ type ListConfig struct {
 	Name     string   `yaml:"name" json:"name"`
 	Enabled  bool     `yaml:"enabled" json:"enabled"`
 	URL      string   `yaml:"url" json:"url"`
+	// Weight multiplies the score of matches against the list, 0 is treated as 1
+	Weight float64 `yaml:"weight" json:"weight"`
+	// Severity is used for matches against the list which do not define their own, empty uses the defaults
+	Severity rules.Severity `yaml:"severity" json:"severity"`
 }
 
 // SteamIDFormat TODO add to steamid pkg.
@@ -121,8 +128,14 @@ func (sm *settingsManager) setup() error {
 	return nil
 }
 
+// ConfigRoot returns the directory containing the config and lists. The config root is relative to the users local
+// config directory, unless it is an absolute path.
 func (sm *settingsManager) ConfigRoot() string {
-	configPath := configdir.LocalConfig(sm.configRoot)
+	configPath := sm.configRoot
+	if !filepath.IsAbs(configPath) {
+		configPath = configdir.LocalConfig(sm.configRoot)
+	}
+
 	if err := configdir.MakePath(configPath); err != nil {
 		return ""
 	}
@@ -139,11 +152,24 @@ func (sm *settingsManager) DBPath() string {
 }
 
 func (sm *settingsManager) LocalPlayerListPath() string {
-	return filepath.Join(sm.ListRoot(), fmt.Sprintf("playerlist.%s.json", rules.LocalRuleName))
+	return sm.localListPath("playerlist")
 }
 
 func (sm *settingsManager) LocalRulesListPath() string {
-	return filepath.Join(sm.ListRoot(), fmt.Sprintf("rules.%s.json", rules.LocalRuleName))
+	return sm.localListPath("rules")
+}
+
+// localListPath returns the path to the local list with the prefix. If a yaml version of the list exists it is used
+// instead of the default json version. Note that comments in yaml lists are not kept when the list is saved.
+func (sm *settingsManager) localListPath(prefix string) string {
+	for _, ext := range []string{"yaml", "yml"} {
+		listPath := filepath.Join(sm.ListRoot(), fmt.Sprintf("%s.%s.%s", prefix, rules.LocalRuleName, ext))
+		if platform.Exists(listPath) {
+			return listPath
+		}
+	}
+
+	return filepath.Join(sm.ListRoot(), fmt.Sprintf("%s.%s.json", prefix, rules.LocalRuleName))
 }
 
 func (sm *settingsManager) LogFilePath() string {
@@ -152,12 +178,13 @@ func (sm *settingsManager) LogFilePath() string {
 
 func (sm *settingsManager) readDefaultOrCreate() (userSettings, error) {
 	var settings userSettings
-	configPath := configdir.LocalConfig(sm.configRoot)
-	if err := configdir.MakePath(configPath); err != nil {
-		return settings, errors.Join(err, errSettingDirectoryCreate)
+	configPath := sm.ConfigRoot()
+	if configPath == "" {
+		return settings, errSettingDirectoryCreate
 	}
 
 	settingsFilePath := filepath.Join(configPath, defaultConfigFileName)
+	sm.configPath = settingsFilePath
 
 	errRead := sm.readFilePath(settingsFilePath, &settings)
 	if errRead != nil {
@@ -183,6 +210,25 @@ func (sm *settingsManager) readDefaultOrCreate() (userSettings, error) {
 		settings.TF2Dir = sm.locateTF2Dir()
 	}
 
+	// Configs created before the taxonomy was added have no attributes, which would otherwise remove all the aliases
+	if len(settings.Attributes) == 0 {
+		settings.Attributes = rules.DefaultAttributes()
+	}
+
+	// Configs created before the log secret was added would launch the game without one, causing every log packet
+	// to be rejected. The secret is saved immediately so the game and listener always agree on it.
+	if settings.UDPListenerSecret == 0 {
+		settings.UDPListenerSecret = randLogSecret()
+
+		sm.settingsMu.Lock()
+		sm.settings = settings
+		sm.settingsMu.Unlock()
+
+		if errSave := sm.writeFilePath(settingsFilePath); errSave != nil {
+			return userSettings{}, errSave
+		}
+	}
+
 	return settings, nil
 }
 
@@ -238,15 +284,51 @@ func (sm *settingsManager) readFilePath(filePath string, settings *userSettings)
 }
 
 func (sm *settingsManager) read(inputFile io.Reader, settings *userSettings) error {
-	if errDecode := yaml.NewDecoder(inputFile).Decode(&settings); errDecode != nil {
+	body, errRead := io.ReadAll(inputFile)
+	if errRead != nil {
+		return errors.Join(errRead, errSettingsDecode)
+	}
+
+	if errDecode := yaml.NewDecoder(bytes.NewReader(body)).Decode(&settings); errDecode != nil {
+		return errors.Join(errDecode, errSettingsDecode)
+	}
+
+	var sections configSections
+	if errDecode := yaml.Unmarshal(body, &sections); errDecode != nil {
 		return errors.Join(errDecode, errSettingsDecode)
 	}
 
+	sections.applyDefaults(settings)
+
 	settings.Rcon = newRconConfig(settings.RCONStatic)
 
 	return nil
 }
 
+// configSections records which of the sections added to the config over time are present in a config file. Configs
+// saved by older versions are missing them, so they are given the defaults rather than zero values. Sections which
+// are present are kept as they are, even when they only contain zero values.
+type configSections struct {
+	MarkTTLDays      *yaml.Node `yaml:"mark_ttl_days"`
+	SpamDetection    *yaml.Node `yaml:"spam_detection"`
+	BotWaveDetection *yaml.Node `yaml:"bot_wave_detection"`
+}
+
+// applyDefaults sets the default values of any of the sections that are missing.
+func (c configSections) applyDefaults(settings *userSettings) {
+	if c.MarkTTLDays == nil {
+		settings.MarkTTLDays = newMarkTTLDays()
+	}
+
+	if c.SpamDetection == nil {
+		settings.SpamDetection = newSpamDetectionConfig()
+	}
+
+	if c.BotWaveDetection == nil {
+		settings.BotWaveDetection = newBotWaveConfig()
+	}
+}
+
 func (sm *settingsManager) save() error {
 	sm.settingsMu.RLock()
 
@@ -325,18 +407,32 @@ type userSettings struct {
 	// eg: -> ~/.local/share/Steam/userdata/123456789/config/localconfig.vdf
 	SteamDir string `yaml:"steam_dir" json:"steam_dir"`
 	// Path to tf2 mod eg: (C:\Program Files (x86)\Steam\steamapps\common\Team Fortress 2\tf)
-	TF2Dir                  string               `yaml:"tf2_dir" json:"tf2_dir"`
-	AutoLaunchGame          bool                 `yaml:"auto_launch_game" json:"auto_launch_game"`
-	AutoCloseOnGameExit     bool                 `yaml:"auto_close_on_game_exit" json:"auto_close_on_game_exit"`
-	BdAPIEnabled            bool                 `yaml:"bd_api_enabled" json:"bd_api_enabled"`
-	BdAPIAddress            string               `yaml:"bd_api_address" json:"bd_api_address"`
-	APIKey                  string               `yaml:"api_key" json:"api_key"`
-	DisconnectedTimeout     string               `yaml:"disconnected_timeout" json:"disconnected_timeout"`
-	DiscordPresenceEnabled  bool                 `yaml:"discord_presence_enabled" json:"discord_presence_enabled"`
-	KickerEnabled           bool                 `yaml:"kicker_enabled" json:"kicker_enabled"`
-	ChatWarningsEnabled     bool                 `yaml:"chat_warnings_enabled" json:"chat_warnings_enabled"`
-	PartyWarningsEnabled    bool                 `yaml:"party_warnings_enabled" json:"party_warnings_enabled"`
-	KickTags                []string             `yaml:"kick_tags" json:"kick_tags"`
+	TF2Dir                 string   `yaml:"tf2_dir" json:"tf2_dir"`
+	AutoLaunchGame         bool     `yaml:"auto_launch_game" json:"auto_launch_game"`
+	AutoCloseOnGameExit    bool     `yaml:"auto_close_on_game_exit" json:"auto_close_on_game_exit"`
+	BdAPIEnabled           bool     `yaml:"bd_api_enabled" json:"bd_api_enabled"`
+	BdAPIAddress           string   `yaml:"bd_api_address" json:"bd_api_address"`
+	APIKey                 string   `yaml:"api_key" json:"api_key"`
+	DisconnectedTimeout    string   `yaml:"disconnected_timeout" json:"disconnected_timeout"`
+	DiscordPresenceEnabled bool     `yaml:"discord_presence_enabled" json:"discord_presence_enabled"`
+	KickerEnabled          bool     `yaml:"kicker_enabled" json:"kicker_enabled"`
+	ChatWarningsEnabled    bool     `yaml:"chat_warnings_enabled" json:"chat_warnings_enabled"`
+	PartyWarningsEnabled   bool     `yaml:"party_warnings_enabled" json:"party_warnings_enabled"`
+	KickTags               []string `yaml:"kick_tags" json:"kick_tags"`
+	// KickScoreThreshold is the minimum suspicion score required to kick a player. 0 kicks any matched player.
+	KickScoreThreshold float64 `yaml:"kick_score_threshold" json:"kick_score_threshold"`
+	// AnnounceScoreThreshold is the minimum suspicion score required to announce a player. 0 announces any matched
+	// player.
+	AnnounceScoreThreshold float64 `yaml:"announce_score_threshold" json:"announce_score_threshold"`
+	// MarkTTLDays is the default number of days a mark with the attribute lasts before expiring. Attributes
+	// without a ttl never expire.
+	MarkTTLDays map[string]int `yaml:"mark_ttl_days" json:"mark_ttl_days"`
+	// Attributes defines the canonical player attributes, their aliases and how they are displayed.
+	Attributes []rules.AttributeDefinition `yaml:"attributes" json:"attributes"`
+	// SpamDetection configures the thresholds used to detect chat spam.
+	SpamDetection SpamDetectionConfig `yaml:"spam_detection" json:"spam_detection"`
+	// BotWaveDetection configures how groups of players joining together are detected.
+	BotWaveDetection        BotWaveConfig        `yaml:"bot_wave_detection" json:"bot_wave_detection"`
 	VoiceBansEnabled        bool                 `yaml:"voice_bans_enabled" json:"voice_bans_enabled"`
 	DebugLogEnabled         bool                 `yaml:"debug_log_enabled" json:"debug_log_enabled"`
 	Lists                   ListConfigCollection `yaml:"lists" json:"lists"`
@@ -351,7 +447,28 @@ type userSettings struct {
 	SystrayEnabled          bool                 `yaml:"systray_enabled" json:"systray_enabled"`
 	UDPListenerEnabled      bool                 `yaml:"udp_listener_enabled" json:"udp_listener_enabled"`
 	UDPListenerAddr         string               `yaml:"udp_listener_addr" json:"udp_listener_addr"`
-	Rcon                    RCONConfig           `yaml:"rcon" json:"rcon"`
+	// UDPListenerSecret is the sv_logsecret the game is launched with, log packets without it are rejected.
+	UDPListenerSecret int32 `yaml:"udp_listener_secret" json:"udp_listener_secret"`
+	// UDPListenerAllowPlain enables accepting plain log packets, sent without sv_logsecret, from the
+	// UDPListenerPlainSources ip addresses or cidr ranges.
+	UDPListenerAllowPlain   bool       `yaml:"udp_listener_allow_plain" json:"udp_listener_allow_plain"`
+	UDPListenerPlainSources []string   `yaml:"udp_listener_plain_sources" json:"udp_listener_plain_sources"`
+	Rcon                    RCONConfig `yaml:"rcon" json:"rcon"`
+}
+
+// newMarkTTLDays returns the default number of days marks with each attribute last before expiring.
+func newMarkTTLDays() map[string]int {
+	return map[string]int{"suspicious": 30, "racist": 90}
+}
+
+// taxonomyAttributes returns the attributes used to create the taxonomy, falling back to the default attributes when
+// none are configured.
+func (s userSettings) taxonomyAttributes() []rules.AttributeDefinition {
+	if len(s.Attributes) == 0 {
+		return rules.DefaultAttributes()
+	}
+
+	return s.Attributes
 }
 
 func newSettings(plat platform.Platform) userSettings {
@@ -369,7 +486,11 @@ func newSettings(plat platform.Platform) userSettings {
 		KickerEnabled:           false,
 		ChatWarningsEnabled:     false,
 		PartyWarningsEnabled:    true,
-		KickTags:                []string{"cheater", "bot", "trigger_name", "trigger_msg"},
+		KickTags:                rules.KickAttributes(rules.DefaultAttributes()),
+		MarkTTLDays:             newMarkTTLDays(),
+		Attributes:              rules.DefaultAttributes(),
+		SpamDetection:           newSpamDetectionConfig(),
+		BotWaveDetection:        newBotWaveConfig(),
 		VoiceBansEnabled:        false,
 		DebugLogEnabled:         false,
 		RunMode:                 ModeRelease,
@@ -379,6 +500,8 @@ func newSettings(plat platform.Platform) userSettings {
 		PlayerDisconnectTimeout: 20,
 		UDPListenerAddr:         "0.0.0.0:27777",
 		UDPListenerEnabled:      false,
+		UDPListenerSecret:       randLogSecret(),
+		UDPListenerPlainSources: []string{"127.0.0.1"},
 		Lists: []*ListConfig{
 			{
 				Name:     "Uncletopia",
@@ -474,6 +597,21 @@ func (s *userSettings) AppURL() string {
 	return fmt.Sprintf("http://%s/", s.HTTPListenAddr)
 }
 
+// MarkExpiry returns the expiry time of each of the attributes which have a default ttl.
+func (s *userSettings) MarkExpiry(attrs []string) map[string]time.Time {
+	expiry := map[string]time.Time{}
+
+	for _, attr := range attrs {
+		for ttlAttr, days := range s.MarkTTLDays {
+			if days > 0 && strings.EqualFold(attr, ttlAttr) {
+				expiry[attr] = time.Now().AddDate(0, 0, days)
+			}
+		}
+	}
+
+	return expiry
+}
+
 func (s *userSettings) AddList(config *ListConfig) error {
 	for _, known := range s.Lists {
 		if config.ListType == known.ListType &&
@@ -512,6 +650,16 @@ func (s *userSettings) Validate() error {
 		}
 	}
 
+	if s.UDPListenerAllowPlain {
+		if _, errSources := parseLogSources(s.UDPListenerPlainSources); errSources != nil {
+			err = errors.Join(err, errSources)
+		}
+	}
+
+	if _, errTaxonomy := rules.NewTaxonomy(s.Attributes); errTaxonomy != nil {
+		err = errors.Join(err, errTaxonomy)
+	}
+
 	return err
 }
 
@@ -542,6 +690,18 @@ func randPort() uint16 {
 	return uint16(binary.LittleEndian.Uint64(b[:]))
 }
 
+// randLogSecret returns a random positive sv_logsecret value.
+func randLogSecret() int32 {
+	const defaultSecret = 27777
+
+	var b [4]byte
+	if _, errRead := rand.Read(b[:]); errRead != nil {
+		return defaultSecret
+	}
+
+	return int32(binary.LittleEndian.Uint32(b[:])%math.MaxInt32) + 1 //nolint:gosec
+}
+
 func newRconConfig(static bool) RCONConfig {
 	if static {
 		return RCONConfig{
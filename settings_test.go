@@ -0,0 +1,102 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leighmacdonald/bd/platform"
+	"github.com/leighmacdonald/bd/rules"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestConfig writes a config file, as saved by an older version, for the settings manager to load.
+func writeTestConfig(t *testing.T, settingsMgr *settingsManager, body string) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(filepath.Join(settingsMgr.ConfigRoot(), defaultConfigFileName), []byte(body), 0o600))
+}
+
+func TestSettingsMissingAttributes(t *testing.T) {
+	settingsMgr := newTestSettingsManager(t)
+	writeTestConfig(t, settingsMgr, "steam_dir: steam\ntf2_dir: tf2\n")
+
+	settings, errRead := settingsMgr.readDefaultOrCreate()
+	require.NoError(t, errRead)
+	require.Equal(t, rules.DefaultAttributes(), settings.Attributes)
+
+	settingsMgr.settings = settings
+
+	engine := createRulesEngine(settingsMgr)
+	require.Equal(t, "cheater", engine.Taxonomy().Canonical("hacker"))
+	require.Equal(t, rules.SeverityCritical, engine.Taxonomy().Severity([]string{"bot"}))
+
+	// An empty list of attributes also uses the defaults rather than removing the taxonomy
+	settingsMgr.settings.Attributes = nil
+	require.Equal(t, "cheater", createRulesEngine(settingsMgr).Taxonomy().Canonical("hacker"))
+}
+
+func TestSettingsMissingLogSecret(t *testing.T) {
+	settingsMgr := newTestSettingsManager(t)
+	writeTestConfig(t, settingsMgr, "steam_id: \"76561197961279983\"\nsteam_dir: steam\ntf2_dir: tf2\nudp_listener_enabled: true\n")
+
+	settings, errRead := settingsMgr.readDefaultOrCreate()
+	require.NoError(t, errRead)
+	require.Positive(t, settings.UDPListenerSecret)
+
+	// The generated secret is saved, so the same one is used the next time the game is launched
+	reloader := newSettingsManager(platform.New())
+	reloader.configRoot = settingsMgr.configRoot
+
+	reloaded, errReload := reloader.readDefaultOrCreate()
+	require.NoError(t, errReload)
+	require.Equal(t, settings.UDPListenerSecret, reloaded.UDPListenerSecret)
+	require.True(t, reloaded.UDPListenerEnabled)
+}
+
+func TestSettingsMissingSpamDetection(t *testing.T) {
+	settingsMgr := newTestSettingsManager(t)
+	writeTestConfig(t, settingsMgr, "steam_id: \"76561197961279983\"\nudp_listener_secret: 1\n")
+
+	settings, errRead := settingsMgr.readDefaultOrCreate()
+	require.NoError(t, errRead)
+	require.Equal(t, newSpamDetectionConfig(), settings.SpamDetection)
+
+	// Sections that are configured are left as they are, even when disabled
+	writeTestConfig(t, settingsMgr, "steam_id: \"76561197961279983\"\nudp_listener_secret: 1\nspam_detection:\n  enabled: false\n")
+
+	configured, errConfigured := settingsMgr.readDefaultOrCreate()
+	require.NoError(t, errConfigured)
+	require.Equal(t, SpamDetectionConfig{}, configured.SpamDetection)
+}
+
+func TestSettingsMissingBotWaveDetection(t *testing.T) {
+	settingsMgr := newTestSettingsManager(t)
+	writeTestConfig(t, settingsMgr, "steam_id: \"76561197961279983\"\nudp_listener_secret: 1\n")
+
+	settings, errRead := settingsMgr.readDefaultOrCreate()
+	require.NoError(t, errRead)
+	require.Equal(t, newBotWaveConfig(), settings.BotWaveDetection)
+
+	writeTestConfig(t, settingsMgr, "steam_id: \"76561197961279983\"\nudp_listener_secret: 1\nbot_wave_detection:\n  enabled: false\n")
+
+	configured, errConfigured := settingsMgr.readDefaultOrCreate()
+	require.NoError(t, errConfigured)
+	require.Equal(t, BotWaveConfig{}, configured.BotWaveDetection)
+}
+
+func TestSettingsMissingMarkTTLDays(t *testing.T) {
+	settingsMgr := newTestSettingsManager(t)
+	writeTestConfig(t, settingsMgr, "steam_id: \"76561197961279983\"\nudp_listener_secret: 1\n")
+
+	settings, errRead := settingsMgr.readDefaultOrCreate()
+	require.NoError(t, errRead)
+	require.Equal(t, newMarkTTLDays(), settings.MarkTTLDays)
+
+	// Removing all the ttls means marks never expire
+	writeTestConfig(t, settingsMgr, "steam_id: \"76561197961279983\"\nudp_listener_secret: 1\nmark_ttl_days: {}\n")
+
+	configured, errConfigured := settingsMgr.readDefaultOrCreate()
+	require.NoError(t, errConfigured)
+	require.Empty(t, configured.MarkTTLDays)
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/leighmacdonald/bd/rules"
+)
+
+const (
+	// spamDetectorOrigin is used as the origin of all matches produced by the spam detector.
+	spamDetectorOrigin = "Spam Detector"
+	// spamAttribute is the attribute given to players detected as spamming.
+	spamAttribute = "spammer"
+	// minLengthSamples is the number of messages that must be seen before the average message length is trusted.
+	minLengthSamples = 20
+)
+
+// SpamDetectionConfig defines the thresholds at which a player's chat is considered spam. Setting any of the
+// counts or the length factor to 0 disables that check.
+type SpamDetectionConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// RepeatCount is the number of identical or near-identical messages sent within RepeatWindow seconds
+	// that is considered spam.
+	RepeatCount  int `yaml:"repeat_count" json:"repeat_count"`
+	RepeatWindow int `yaml:"repeat_window" json:"repeat_window"`
+	// RepeatSimilarity is the 0.0-1.0 similarity at which two messages are considered the same.
+	RepeatSimilarity float64 `yaml:"repeat_similarity" json:"repeat_similarity"`
+	// RateCount is the number of messages sent within RateWindow seconds that is considered spam.
+	RateCount  int `yaml:"rate_count" json:"rate_count"`
+	RateWindow int `yaml:"rate_window" json:"rate_window"`
+	// LengthFactor is how many times longer than the average message a message must be to be considered spam.
+	LengthFactor float64 `yaml:"length_factor" json:"length_factor"`
+	// MinLength is the shortest message that can be considered too long.
+	MinLength int `yaml:"min_length" json:"min_length"`
+}
+
+func newSpamDetectionConfig() SpamDetectionConfig {
+	return SpamDetectionConfig{
+		Enabled:          true,
+		RepeatCount:      3,
+		RepeatWindow:     120,
+		RepeatSimilarity: 0.85,
+		RateCount:        6,
+		RateWindow:       10,
+		LengthFactor:     4,
+		MinLength:        100,
+	}
+}
+
+type chatEntry struct {
+	message string
+	sentAt  time.Time
+}
+
+// spamDetector watches the in game chat for players that repeat the same message, send messages faster than a
+// person reasonably could, or send messages far longer than normal. These are the traits of chat spam bots, which
+// often rotate their messages faster than text rules can be written for them.
+type spamDetector struct {
+	incoming    chan LogEvent
+	detections  chan<- detectionEvent
+	settings    *settingsManager
+	history     map[string][]chatEntry
+	totalLength int
+	totalCount  int
+}
+
+func newSpamDetector(settings *settingsManager, ingest *eventBroadcaster, detections chan<- detectionEvent) *spamDetector {
+	detector := &spamDetector{
+		incoming:   make(chan LogEvent),
+		detections: detections,
+		settings:   settings,
+		history:    map[string][]chatEntry{},
+	}
+
+	ingest.registerConsumer(detector.incoming, EvtMsg)
+
+	return detector
+}
+
+func (d *spamDetector) start(ctx context.Context) {
+	for {
+		select {
+		case evt := <-d.incoming:
+			config := d.settings.Settings().SpamDetection
+			if !config.Enabled {
+				continue
+			}
+
+			matches := d.check(evt, config)
+			if len(matches) == 0 {
+				continue
+			}
+
+			select {
+			case d.detections <- detectionEvent{name: evt.Player, matches: matches, proof: evt.Message}:
+			case <-ctx.Done():
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// check records the message and returns a match for each of the spam checks it fails.
+func (d *spamDetector) check(evt LogEvent, config SpamDetectionConfig) []rules.MatchResult {
+	var (
+		matches []rules.MatchResult
+		message = strings.TrimSpace(evt.Message)
+		sentAt  = evt.Timestamp
+	)
+
+	if sentAt.IsZero() {
+		sentAt = time.Now()
+	}
+
+	d.prune(sentAt, time.Second*time.Duration(max(config.RepeatWindow, config.RateWindow)))
+
+	history := d.history[evt.Player]
+
+	if config.RepeatCount > 0 {
+		var (
+			repeats = 1
+			normal  = strings.ToLower(message)
+		)
+
+		for _, entry := range history {
+			if sentAt.Sub(entry.sentAt) <= time.Second*time.Duration(config.RepeatWindow) &&
+				rules.Similarity(strings.ToLower(entry.message), normal) >= config.RepeatSimilarity {
+				repeats++
+			}
+		}
+
+		if repeats >= config.RepeatCount {
+			slog.Debug("Player repeated message", slog.String("name", evt.Player), slog.Int("repeats", repeats))
+
+			matches = append(matches, newSpamMatch("chat_repeat",
+				fmt.Sprintf("Sent the same message %d or more times within %ds", config.RepeatCount, config.RepeatWindow)))
+		}
+	}
+
+	if config.RateCount > 0 {
+		sent := 1
+
+		for _, entry := range history {
+			if sentAt.Sub(entry.sentAt) <= time.Second*time.Duration(config.RateWindow) {
+				sent++
+			}
+		}
+
+		if sent >= config.RateCount {
+			slog.Debug("Player exceeded message rate", slog.String("name", evt.Player), slog.Int("sent", sent))
+
+			matches = append(matches, newSpamMatch("chat_rate",
+				fmt.Sprintf("Sent %d or more messages within %ds", config.RateCount, config.RateWindow)))
+		}
+	}
+
+	length := len([]rune(message))
+
+	if config.LengthFactor > 0 && length >= config.MinLength && d.totalCount >= minLengthSamples {
+		average := float64(d.totalLength) / float64(d.totalCount)
+		if float64(length) >= average*config.LengthFactor {
+			slog.Debug("Player sent long message", slog.String("name", evt.Player),
+				slog.Int("length", length), slog.Float64("average", average))
+
+			matches = append(matches, newSpamMatch("chat_length",
+				fmt.Sprintf("Sent a message %.1fx longer than average", config.LengthFactor)))
+		}
+	}
+
+	d.totalLength += length
+	d.totalCount++
+	d.history[evt.Player] = append(history, chatEntry{message: message, sentAt: sentAt})
+
+	return matches
+}
+
+// prune removes any messages older than the window, along with players that have no recent messages.
+func (d *spamDetector) prune(now time.Time, window time.Duration) {
+	for name, entries := range d.history {
+		recent := entries[:0]
+
+		for _, entry := range entries {
+			if now.Sub(entry.sentAt) <= window {
+				recent = append(recent, entry)
+			}
+		}
+
+		if len(recent) == 0 {
+			delete(d.history, name)
+		} else {
+			d.history[name] = recent
+		}
+	}
+}
+
+// newSpamMatch creates a match for the check. The description only depends on the config, rather than the message
+// that triggered it, so that a player who keeps spamming does not accumulate a new match for every message.
+func newSpamMatch(matcherType string, description string) rules.MatchResult {
+	return rules.MatchResult{
+		Origin:      spamDetectorOrigin,
+		Attributes:  []string{spamAttribute},
+		MatcherType: matcherType,
+		Description: description,
+		Severity:    rules.SeverityLow,
+		Weight:      rules.SeverityLow.Weight(),
+	}
+}
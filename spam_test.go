@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/bd/platform"
+	"github.com/leighmacdonald/bd/rules"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpamDetector(t *testing.T) {
+	var (
+		detector = &spamDetector{history: map[string][]chatEntry{}}
+		config   = newSpamDetectionConfig()
+		start    = time.Now()
+	)
+
+	send := func(name string, message string, offset time.Duration) []string {
+		var types []string
+
+		for _, match := range detector.check(LogEvent{Player: name, Message: message, Timestamp: start.Add(offset)}, config) {
+			require.Equal(t, spamDetectorOrigin, match.Origin)
+			types = append(types, match.MatcherType)
+		}
+
+		return types
+	}
+
+	// Establish a baseline of normal chat
+	for i := range minLengthSamples {
+		require.Empty(t, send(fmt.Sprintf("player %d", i), "gg", time.Duration(i)*time.Second))
+	}
+
+	// Near-identical messages are counted as repeats
+	require.Empty(t, send("bot", "join my discord for free hats", time.Second*30))
+	require.Empty(t, send("bot", "JOIN my discord for free hats!", time.Second*50))
+	require.Equal(t, []string{"chat_repeat"}, send("bot", "join my discord for free hatz", time.Second*70))
+
+	// Repeats outside the window are forgotten
+	require.Empty(t, send("bot", "join my discord for free hats", time.Second*300))
+
+	for _, message := range []string{"hello", "anyone there", "nice shot", "lol", "who is medic"}[:config.RateCount-1] {
+		require.Empty(t, send("fast", message, time.Second*400))
+	}
+
+	require.Equal(t, []string{"chat_rate"}, send("fast", "one too many", time.Second*401))
+
+	require.Equal(t, []string{"chat_length"}, send("long", strings.Repeat("spam ", 30), time.Second*500))
+
+	config.LengthFactor = 0
+	require.Empty(t, send("long2", strings.Repeat("spam ", 30), time.Second*600))
+}
+
+func TestSpamDetectorRepeatedMatches(t *testing.T) {
+	var (
+		detector = &spamDetector{history: map[string][]chatEntry{}}
+		config   = newSpamDetectionConfig()
+		state    = newGameState(nil, newSettingsManager(platform.New()), newPlayerStates(), nil, nil, rules.New(), nil)
+		botSID   = steamid.New(76561197961279983)
+		start    = time.Now()
+	)
+
+	state.players.update(PlayerState{SteamID: botSID, Personaname: "bot"})
+
+	for i := range config.RepeatCount + 5 {
+		evt := LogEvent{Player: "bot", Message: "join my discord for free hats", Timestamp: start.Add(time.Duration(i) * time.Second)}
+		if matches := detector.check(evt, config); len(matches) > 0 {
+			state.onDetection(detectionEvent{name: evt.Player, matches: matches, proof: evt.Message})
+		}
+	}
+
+	player, errPlayer := state.players.bySteamID(botSID)
+	require.NoError(t, errPlayer)
+
+	var types []string
+	for _, match := range player.Matches {
+		types = append(types, match.MatcherType)
+	}
+
+	// Every message past the threshold fails the same checks, but each check should only match once
+	require.ElementsMatch(t, []string{"chat_repeat", "chat_rate"}, types)
+	require.InDelta(t, 2*rules.SeverityLow.Weight(), player.Suspicion.Total, 0.001)
+}
@@ -5,7 +5,9 @@
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"net"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -154,16 +156,22 @@ type gameState struct {
 	playerDataChan     chan playerDataUpdate
 	profileUpdateQueue chan steamid.SteamID
 	eventChan          chan LogEvent
+	detectionChan      chan detectionEvent
+	announceChan       chan announcement
 	settings           *settingsManager
 	players            *playerStates
 	db                 store.Querier
 	server             serverState
 	store              store.Querier
 	rcon               rconConnection
+	re                 *rules.Engine
+	local              *localLists
+	waves              *botWaveDetector
+	announced          *announcedMatches
 }
 
 func newGameState(store store.Querier, settings *settingsManager, playerState *playerStates, rcon rconConnection,
-	db store.Querier,
+	db store.Querier, re *rules.Engine, local *localLists,
 ) *gameState {
 	return &gameState{
 		mu:                 &sync.RWMutex{},
@@ -172,9 +180,15 @@ func newGameState(store store.Querier, settings *settingsManager, playerState *p
 		players:            playerState,
 		rcon:               rcon,
 		db:                 db,
+		re:                 re,
+		local:              local,
+		waves:              newBotWaveDetector(),
+		announced:          newAnnouncedMatches(),
 		server:             serverState{},
 		playerDataChan:     make(chan playerDataUpdate),
 		eventChan:          make(chan LogEvent),
+		detectionChan:      make(chan detectionEvent),
+		announceChan:       make(chan announcement, announceQueueSize),
 		profileUpdateQueue: make(chan steamid.SteamID),
 	}
 }
@@ -184,6 +198,8 @@ func (s *gameState) start(ctx context.Context) {
 		select {
 		case playerData := <-s.playerDataChan:
 			s.applyRemoteData(ctx, playerData)
+		case detection := <-s.detectionChan:
+			s.onDetection(detection)
 		case evt := <-s.eventChan:
 			// slog.Debug("received event", slog.Int("type", int(evt.Type)))
 			switch evt.Type { //nolint:exhaustive
@@ -219,9 +235,23 @@ func (s *gameState) start(ctx context.Context) {
 			case EvtDisconnect:
 				s.onMapChange()
 			case EvtKill:
-				s.onKill(killEvent{victimName: evt.Victim, sourceName: evt.Player})
+				s.onKill(killEvent{
+					sourceName:   evt.Player,
+					victimName:   evt.Victim,
+					assisterName: evt.Assister,
+					weapon:       evt.Weapon,
+					crit:         evt.Crit,
+				})
+			case EvtSuicide:
+				s.onKill(killEvent{sourceName: evt.Player, victimName: evt.Victim, weapon: evt.Weapon, suicide: true})
 			case EvtMsg:
+				s.onMessage(evt)
 			case EvtConnect:
+				s.waves.connected(evt.Player)
+			case EvtTeam:
+				s.onTeam(teamEvent{name: evt.Player, team: evt.Team})
+			case EvtClass:
+				s.onClass(classEvent{class: evt.Class})
 			case EvtLobby:
 			case EvtAny:
 			}
@@ -267,6 +297,7 @@ func (s *gameState) startCleanupHandler(ctx context.Context, settingsMgr *settin
 
 				if player.IsExpired() {
 					s.players.remove(player.SteamID)
+					s.announced.remove(player.SteamID)
 					slog.Debug("Flushing expired player", slog.String("steam_id", player.SteamID.String()))
 				}
 			}
@@ -287,6 +318,7 @@ func (s *gameState) applyRemoteData(ctx context.Context, data playerDataUpdate)
 
 	// Summary
 	player.AvatarHash = data.summary.AvatarHash
+	player.AvatarPerceptualHash = data.avatarPerceptualHash
 	player.AccountCreatedOn = time.Unix(int64(data.summary.TimeCreated), 0)
 	player.Visibility = int64(data.summary.CommunityVisibilityState)
 
@@ -312,6 +344,9 @@ func (s *gameState) applyRemoteData(ctx context.Context, data playerDataUpdate)
 		}
 	}
 
+	// Avatar
+	player = s.applyRuleMatches(player, data.avatarMatches, player.AvatarHash)
+
 	// meta
 	player.UpdatedOn = time.Now()
 	player.ProfileUpdatedOn = player.UpdatedOn
@@ -324,6 +359,10 @@ func (s *gameState) applyRemoteData(ctx context.Context, data playerDataUpdate)
 		return
 	}
 	s.players.update(player)
+
+	// The account age decides which player is trusted when they share a name
+	s.detectImpersonation()
+	s.detectBotWave(ctx)
 }
 
 func (s *gameState) CurrentServerState() serverState {
@@ -333,15 +372,38 @@ func (s *gameState) CurrentServerState() serverState {
 	return s.server
 }
 
+// onKill updates the kill feed stats of the players involved in the kill. Each weapon used by the killer is tracked
+// separately, since an unusual weapon distribution, such as only landing crits with a sniper rifle, is a good
+// indicator of cheating.
 func (s *gameState) onKill(evt killEvent) {
 	ourSid := s.settings.Settings().SteamID
 
+	if evt.suicide {
+		victim, errVictim := s.players.byName(evt.victimName)
+		if errVictim != nil {
+			return
+		}
+
+		victim.Deaths++
+		victim.Suicides++
+
+		s.players.update(victim)
+
+		return
+	}
+
 	src, srcErr := s.players.byName(evt.sourceName)
+	if srcErr != nil && evt.assisterName != "" {
+		// The separator may be part of the killers name rather than an assist
+		src, srcErr = s.players.byName(evt.sourceName + assistSeparator + evt.assisterName)
+		evt.assisterName = ""
+	}
+
 	if srcErr != nil {
 		return
 	}
 
-	target, targetErr := s.players.byName(evt.sourceName)
+	target, targetErr := s.players.byName(evt.victimName)
 	if targetErr != nil {
 		return
 	}
@@ -349,6 +411,24 @@ func (s *gameState) onKill(evt killEvent) {
 	src.Kills++
 	target.Deaths++
 
+	if evt.weapon != "" {
+		// Player states are shared by value, so the map is copied rather than modified in place
+		weapons := maps.Clone(src.Weapons)
+		if weapons == nil {
+			weapons = map[string]WeaponStats{}
+		}
+
+		stats := weapons[evt.weapon]
+		stats.Kills++
+
+		if evt.crit {
+			stats.Crits++
+		}
+
+		weapons[evt.weapon] = stats
+		src.Weapons = weapons
+	}
+
 	if target.SteamID == ourSid {
 		src.DeathsBy++
 	}
@@ -359,6 +439,48 @@ func (s *gameState) onKill(evt killEvent) {
 
 	s.players.update(src)
 	s.players.update(target)
+
+	if evt.assisterName != "" {
+		if assister, errAssister := s.players.byName(evt.assisterName); errAssister == nil {
+			assister.Assists++
+			s.players.update(assister)
+		}
+	}
+}
+
+// onTeam updates the players team as soon as they join it, rather than waiting for the next g15_dumpplayer poll, so
+// that kick targeting and team announcements use the correct team.
+func (s *gameState) onTeam(evt teamEvent) {
+	player, errPlayer := s.players.byName(evt.name)
+	if errPlayer != nil {
+		return
+	}
+
+	if player.Team != evt.team {
+		slog.Debug("Player changed team", sidAttr(player.SteamID), slog.String("team", evt.team.String()))
+	}
+
+	player.Team = evt.team
+	player.UpdatedOn = time.Now()
+
+	s.players.update(player)
+}
+
+// onClass updates our own class. Other players class changes are not shown in the console.
+func (s *gameState) onClass(evt classEvent) {
+	if evt.class == ClassUnknown {
+		return
+	}
+
+	player, errPlayer := s.players.bySteamID(s.settings.Settings().SteamID)
+	if errPlayer != nil {
+		return
+	}
+
+	player.Class = evt.class
+	player.UpdatedOn = time.Now()
+
+	s.players.update(player)
 }
 
 func (s *gameState) getPlayerOrCreate(ctx context.Context, steamID steamid.SteamID) (PlayerState, error) {
@@ -392,6 +514,8 @@ func (s *gameState) onStatus(ctx context.Context, steamID steamid.SteamID, evt s
 	player.IsConnected = true
 	player.UserID = evt.userID
 
+	s.waves.seen(player.SteamID, evt.name, evt.connected)
+
 	if player.Personaname != evt.name {
 		player.Personaname = evt.name
 		errAddName := s.store.UserNameSave(ctx, store.UserNameSaveParams{
@@ -409,9 +533,16 @@ func (s *gameState) onStatus(ctx context.Context, steamID steamid.SteamID, evt s
 				slog.Error("Could not save new user name", errAttr(errAddName))
 			}
 		}
+
+		player = s.applyRuleMatches(player, s.re.MatchPlayer(rules.PlayerInput{
+			Name:                 player.Personaname,
+			AvatarHash:           player.AvatarHash,
+			AvatarPerceptualHash: player.AvatarPerceptualHash,
+		}), player.Personaname)
 	}
 
 	s.players.update(player)
+	s.detectImpersonation()
 
 	// Trigger update of external data if its been long enough, or the player is new to us.
 	if time.Since(player.ProfileUpdatedOn) > time.Hour*24 {
@@ -428,6 +559,211 @@ func (s *gameState) onStatus(ctx context.Context, steamID steamid.SteamID, evt s
 		slog.Int("connected", int(evt.connected.Seconds())))
 }
 
+func (s *gameState) onMessage(evt LogEvent) {
+	player, errPlayer := s.players.byName(evt.Player)
+	if errPlayer != nil {
+		return
+	}
+
+	matches := s.re.MatchPlayer(rules.PlayerInput{
+		Name:                 player.Personaname,
+		Messages:             []string{evt.Message},
+		AvatarHash:           player.AvatarHash,
+		AvatarPerceptualHash: player.AvatarPerceptualHash,
+	})
+	if len(matches) == 0 {
+		return
+	}
+
+	s.players.update(s.applyRuleMatches(player, matches, evt.Message))
+}
+
+// detectionEvent contains the matches produced by one of the behavioural detectors, rather than the rules engine.
+type detectionEvent struct {
+	name    string
+	matches []rules.MatchResult
+	proof   string
+}
+
+func (s *gameState) onDetection(evt detectionEvent) {
+	player, errPlayer := s.players.byName(evt.name)
+	if errPlayer != nil {
+		return
+	}
+
+	s.players.update(s.applyRuleMatches(player, evt.matches, evt.proof))
+}
+
+// announcement is a request for the overwatch to announce new matches against a player.
+type announcement struct {
+	player  PlayerState
+	matches []rules.MatchResult
+}
+
+// announcedMatches tracks the matches which have already been announced for each player, so that a match that is
+// removed and later found again is not announced a second time.
+type announcedMatches struct {
+	sync.Mutex
+	players map[steamid.SteamID][]rules.MatchResult
+}
+
+func newAnnouncedMatches() *announcedMatches {
+	return &announcedMatches{players: map[steamid.SteamID][]rules.MatchResult{}}
+}
+
+// unannounced returns the matches which have not yet been announced for the player.
+func (a *announcedMatches) unannounced(steamID steamid.SteamID, matches []rules.MatchResult) []rules.MatchResult {
+	a.Lock()
+	defer a.Unlock()
+
+	var pending []rules.MatchResult
+
+	for _, match := range matches {
+		same := func(known rules.MatchResult) bool { return sameMatch(known, match) }
+		if !slices.ContainsFunc(a.players[steamID], same) && !slices.ContainsFunc(pending, same) {
+			pending = append(pending, match)
+		}
+	}
+
+	return pending
+}
+
+func (a *announcedMatches) add(steamID steamid.SteamID, matches []rules.MatchResult) {
+	a.Lock()
+	defer a.Unlock()
+
+	a.players[steamID] = append(a.players[steamID], matches...)
+}
+
+func (a *announcedMatches) remove(steamID steamid.SteamID) {
+	a.Lock()
+	defer a.Unlock()
+
+	delete(a.players, steamID)
+}
+
+// announce queues the matches to be announced. Matches which have already been announced for the player are
+// skipped. Announcements are dropped rather than blocking the game state when the queue is full.
+func (s *gameState) announce(player PlayerState, matches []rules.MatchResult) {
+	pending := s.announced.unannounced(player.SteamID, matches)
+	if len(pending) == 0 {
+		return
+	}
+
+	select {
+	case s.announceChan <- announcement{player: player, matches: pending}:
+		s.announced.add(player.SteamID, pending)
+	default:
+		slog.Warn("Announcement queue full, dropping announcement", sidAttr(player.SteamID))
+	}
+}
+
+// detectImpersonation compares the names of all the connected players, including our own, and flags any player
+// sharing a name with a more trusted player. Impersonation matches which no longer apply, such as after the player
+// changes their name or the other player turns out to be the newer account, are removed.
+func (s *gameState) detectImpersonation() {
+	var (
+		connected []PlayerState
+		targets   = map[steamid.SteamID]rules.MatchResult{}
+	)
+
+	for _, player := range s.players.all() {
+		if player.IsConnected {
+			connected = append(connected, player)
+		}
+	}
+
+	for _, found := range findImpersonators(connected, s.settings.Settings().SteamID) {
+		targets[found.impersonator.SteamID] = newImpersonationMatch(found.target.SteamID)
+	}
+
+	for _, player := range connected {
+		match, impersonating := targets[player.SteamID]
+
+		current := slices.DeleteFunc(slices.Clone(player.Matches), func(existing rules.MatchResult) bool {
+			return existing.Origin == impersonationOrigin && (!impersonating || existing.Pattern != match.Pattern)
+		})
+
+		if len(current) != len(player.Matches) {
+			slog.Info("Removed stale impersonation match", sidAttr(player.SteamID), slog.String("name", player.Personaname))
+
+			player.Matches = current
+			player.Suspicion = s.re.Suspicion(player.suspicionInput())
+			s.players.update(player)
+		}
+
+		if !impersonating || player.hasMatch(match) {
+			continue
+		}
+
+		slog.Info("Detected player impersonation", sidAttr(player.SteamID),
+			slog.String("name", player.Personaname), slog.String("target", match.Pattern))
+
+		s.players.update(s.applyRuleMatches(player, []rules.MatchResult{match}, player.Personaname))
+	}
+}
+
+// applyRuleMatches records any new rule matches against the player and performs the actions defined by the rules
+// that triggered them. Players are tagged with any transient marks for the current session, while marks are
+// saved to the local player list along with the proof. The players suspicion score is recalculated afterwards,
+// and the new matches are announced.
+func (s *gameState) applyRuleMatches(player PlayerState, matches []rules.MatchResult, proof string) PlayerState {
+	var added []rules.MatchResult
+
+	for _, match := range matches {
+		if player.hasMatch(match) {
+			continue
+		}
+
+		player.Matches = append(player.Matches, match)
+		added = append(added, match)
+
+		for _, attr := range match.TransientMark {
+			if !slices.Contains(player.TransientMarks, attr) {
+				player.TransientMarks = append(player.TransientMarks, attr)
+			}
+		}
+
+		if len(match.Mark) == 0 {
+			continue
+		}
+
+		settings := s.settings.Settings()
+
+		errMark := s.re.Mark(rules.MarkOpts{
+			SteamID:         player.SteamID,
+			Attributes:      match.Mark,
+			Proof:           []string{proof},
+			Name:            player.Personaname,
+			AttributeExpiry: settings.MarkExpiry(match.Mark),
+		})
+		if errMark != nil {
+			if !errors.Is(errMark, rules.ErrDuplicateSteamID) {
+				slog.Error("Failed to mark player from rule", errAttr(errMark), sidAttr(player.SteamID))
+			}
+
+			continue
+		}
+
+		s.local.savePlayers()
+
+		slog.Info("Marked player from rule", sidAttr(player.SteamID),
+			slog.String("origin", match.Origin), slog.String("description", match.Description))
+
+		for _, steamMatch := range s.re.MatchSteam(player.SteamID) {
+			if !player.hasMatch(steamMatch) {
+				player.Matches = append(player.Matches, steamMatch)
+			}
+		}
+	}
+
+	player.Suspicion = s.re.Suspicion(player.suspicionInput())
+
+	s.announce(player, added)
+
+	return player
+}
+
 func (s *gameState) onTags(evt tagsEvent) {
 	s.mu.Lock()
 	s.server.Tags = evt.tags
@@ -461,6 +797,9 @@ func (s *gameState) onMapChange() {
 		player.IsConnected = true
 		player.Kills = 0
 		player.Deaths = 0
+		player.Assists = 0
+		player.Suicides = 0
+		player.Weapons = nil
 		player.MapTimeStart = time.Now()
 		player.MapTime = 0
 
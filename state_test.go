@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/leighmacdonald/bd/platform"
+	"github.com/leighmacdonald/bd/rules"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKillStats(t *testing.T) {
+	state := newGameState(nil, newSettingsManager(platform.New()), newPlayerStates(), nil, nil, nil, nil)
+
+	for _, player := range []PlayerState{
+		{SteamID: steamid.New(76561197970669109), Personaname: "Sydney"},
+		{SteamID: steamid.New(76561197992870439), Personaname: "Kracken"},
+		{SteamID: steamid.New(76561198004429398), Personaname: "Medic"},
+		{SteamID: steamid.New(76561198000000001), Personaname: "Mr + Plus"},
+	} {
+		state.players.update(player)
+	}
+
+	byName := func(name string) PlayerState {
+		player, errPlayer := state.players.byName(name)
+		require.NoError(t, errPlayer)
+
+		return player
+	}
+
+	state.onKill(killEvent{sourceName: "Sydney", victimName: "Kracken", weapon: "sniperrifle", crit: true})
+	state.onKill(killEvent{sourceName: "Sydney", victimName: "Kracken", weapon: "sniperrifle", crit: true})
+	state.onKill(killEvent{sourceName: "Sydney", assisterName: "Medic", victimName: "Kracken", weapon: "smg"})
+	state.onKill(killEvent{sourceName: "Mr", assisterName: "Plus", victimName: "Sydney", weapon: "club"})
+	state.onKill(killEvent{sourceName: "Kracken", victimName: "Kracken", weapon: weaponWorld, suicide: true})
+
+	sydney := byName("Sydney")
+	require.Equal(t, 3, sydney.Kills)
+	require.Equal(t, 1, sydney.Deaths)
+	require.Equal(t, map[string]WeaponStats{
+		"sniperrifle": {Kills: 2, Crits: 2},
+		"smg":         {Kills: 1},
+	}, sydney.Weapons)
+
+	kracken := byName("Kracken")
+	require.Equal(t, 4, kracken.Deaths)
+	require.Equal(t, 1, kracken.Suicides)
+	require.Empty(t, kracken.Weapons)
+
+	require.Equal(t, 1, byName("Medic").Assists)
+	require.Equal(t, map[string]WeaponStats{"club": {Kills: 1}}, byName("Mr + Plus").Weapons)
+
+	state.onMapChange()
+	require.Empty(t, byName("Sydney").Weapons)
+}
+
+func TestTeamAndClass(t *testing.T) {
+	var (
+		settings = newSettingsManager(platform.New())
+		state    = newGameState(nil, settings, newPlayerStates(), nil, nil, nil, nil)
+		ourSID   = steamid.New(76561197970669109)
+		otherSID = steamid.New(76561197992870439)
+	)
+
+	settings.settings.SteamID = ourSID
+
+	state.players.update(PlayerState{SteamID: ourSID, Personaname: "Sydney", Team: Red})
+	state.players.update(PlayerState{SteamID: otherSID, Personaname: "Kracken", Team: Red})
+
+	state.onTeam(teamEvent{name: "Kracken", team: Blu})
+	state.onTeam(teamEvent{name: "Unknown", team: Blu})
+	state.onClass(classEvent{class: Medic})
+
+	other, errOther := state.players.bySteamID(otherSID)
+	require.NoError(t, errOther)
+	require.Equal(t, Blu, other.Team)
+	require.Equal(t, ClassUnknown, other.Class)
+
+	state.onTeam(teamEvent{name: "Kracken", team: Spec})
+
+	other, errOther = state.players.bySteamID(otherSID)
+	require.NoError(t, errOther)
+	require.Equal(t, Spec, other.Team)
+
+	us, errUs := state.players.bySteamID(ourSID)
+	require.NoError(t, errUs)
+	require.Equal(t, Red, us.Team)
+	require.Equal(t, Medic, us.Class)
+}
+
+func TestMessageAvatarRule(t *testing.T) {
+	var (
+		engine = rules.New()
+		state  = newGameState(nil, newSettingsManager(platform.New()), newPlayerStates(), nil, nil, engine, nil)
+		botSID = steamid.New(76561197961279983)
+		hash   = "00ff00ff00ff00ff"
+	)
+
+	_, errRule := engine.AddRule(rules.RuleDefinition{
+		Description: "skin scam bots",
+		Triggers: rules.RuleTriggers{
+			Mode:             rules.RuleTriggerModeMatchAll,
+			ChatMsgTextMatch: &rules.RuleTriggerTextMatch{Mode: rules.TextMatchModeContains, Patterns: []string{"free skins"}},
+			AvatarMatch:      []rules.RuleTriggerAvatarMatch{{Mode: rules.AvatarMatchPerceptual, AvatarHash: hash}},
+		},
+	})
+	require.NoError(t, errRule)
+
+	state.players.update(PlayerState{SteamID: botSID, Personaname: "bot"})
+	state.onMessage(LogEvent{Type: EvtMsg, Player: "bot", Message: "free skins"})
+
+	player, errPlayer := state.players.bySteamID(botSID)
+	require.NoError(t, errPlayer)
+	require.Empty(t, player.Matches, "The rule should not match until the avatar is known")
+
+	player.AvatarPerceptualHash = hash
+	state.players.update(player)
+	state.onMessage(LogEvent{Type: EvtMsg, Player: "bot", Message: "free skins"})
+
+	player, errPlayer = state.players.bySteamID(botSID)
+	require.NoError(t, errPlayer)
+	require.Len(t, player.Matches, 1)
+}
+
+func TestAnnounceKeepsCurrentState(t *testing.T) {
+	var (
+		settings = newSettingsManager(platform.New())
+		state    = newGameState(nil, settings, newPlayerStates(), nil, nil, rules.New(), nil)
+		watch    = newOverwatch(settings, nil, state)
+		botSID   = steamid.New(76561197961279983)
+		snapshot = PlayerState{
+			SteamID:     botSID,
+			Personaname: "bot",
+			Whitelist:   true,
+			Suspicion:   rules.SuspicionScore{Total: rules.MaxSuspicionScore},
+		}
+		match = rules.MatchResult{Origin: "test", MatcherType: "name", Description: "bot"}
+	)
+
+	// The game state has updated the player since the snapshot was queued for announcement
+	current := snapshot
+	current.Kills = 10
+	state.players.update(current)
+
+	watch.announceMatch(context.Background(), snapshot, []rules.MatchResult{match})
+
+	player, errPlayer := state.players.bySteamID(botSID)
+	require.NoError(t, errPlayer)
+	require.Equal(t, 10, player.Kills)
+	require.False(t, player.AnnouncedGeneralLast.IsZero())
+}
+
+func TestAnnounceNewMatches(t *testing.T) {
+	var (
+		state  = newGameState(nil, newSettingsManager(platform.New()), newPlayerStates(), nil, nil, rules.New(), nil)
+		botSID = steamid.New(76561197961279983)
+		match  = newSpamMatch("chat_repeat", "Sent the same message 3 or more times within 10s")
+	)
+
+	state.players.update(PlayerState{SteamID: botSID, Personaname: "bot"})
+	state.onDetection(detectionEvent{name: "bot", matches: []rules.MatchResult{match}, proof: "spam"})
+
+	evt := <-state.announceChan
+	require.Equal(t, botSID, evt.player.SteamID)
+	require.Equal(t, []rules.MatchResult{match}, evt.matches)
+
+	// The match is already known, so it is not announced again
+	state.onDetection(detectionEvent{name: "bot", matches: []rules.MatchResult{match}, proof: "spam"})
+
+	// A match that was removed and found again has already been announced
+	player, errPlayer := state.players.bySteamID(botSID)
+	require.NoError(t, errPlayer)
+
+	player.Matches = nil
+	state.players.update(player)
+	state.onDetection(detectionEvent{name: "bot", matches: []rules.MatchResult{match}, proof: "spam"})
+
+	require.Empty(t, state.announceChan)
+}
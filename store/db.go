@@ -51,6 +51,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.messagesStmt, err = db.PrepareContext(ctx, messages); err != nil {
 		return nil, fmt.Errorf("error preparing query Messages: %w", err)
 	}
+	if q.messagesAllStmt, err = db.PrepareContext(ctx, messagesAll); err != nil {
+		return nil, fmt.Errorf("error preparing query MessagesAll: %w", err)
+	}
 	if q.playerStmt, err = db.PrepareContext(ctx, player); err != nil {
 		return nil, fmt.Errorf("error preparing query Player: %w", err)
 	}
@@ -63,6 +66,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.playerUpdateStmt, err = db.PrepareContext(ctx, playerUpdate); err != nil {
 		return nil, fmt.Errorf("error preparing query PlayerUpdate: %w", err)
 	}
+	if q.playersWhitelistedStmt, err = db.PrepareContext(ctx, playersWhitelisted); err != nil {
+		return nil, fmt.Errorf("error preparing query PlayersWhitelisted: %w", err)
+	}
 	if q.sourcebansStmt, err = db.PrepareContext(ctx, sourcebans); err != nil {
 		return nil, fmt.Errorf("error preparing query Sourcebans: %w", err)
 	}
@@ -78,6 +84,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.userNamesStmt, err = db.PrepareContext(ctx, userNames); err != nil {
 		return nil, fmt.Errorf("error preparing query UserNames: %w", err)
 	}
+	if q.userNamesAllStmt, err = db.PrepareContext(ctx, userNamesAll); err != nil {
+		return nil, fmt.Errorf("error preparing query UserNamesAll: %w", err)
+	}
 	return &q, nil
 }
 
@@ -128,6 +137,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing messagesStmt: %w", cerr)
 		}
 	}
+	if q.messagesAllStmt != nil {
+		if cerr := q.messagesAllStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing messagesAllStmt: %w", cerr)
+		}
+	}
 	if q.playerStmt != nil {
 		if cerr := q.playerStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing playerStmt: %w", cerr)
@@ -148,6 +162,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing playerUpdateStmt: %w", cerr)
 		}
 	}
+	if q.playersWhitelistedStmt != nil {
+		if cerr := q.playersWhitelistedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing playersWhitelistedStmt: %w", cerr)
+		}
+	}
 	if q.sourcebansStmt != nil {
 		if cerr := q.sourcebansStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing sourcebansStmt: %w", cerr)
@@ -173,6 +192,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing userNamesStmt: %w", cerr)
 		}
 	}
+	if q.userNamesAllStmt != nil {
+		if cerr := q.userNamesAllStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing userNamesAllStmt: %w", cerr)
+		}
+	}
 	return err
 }
 
@@ -210,49 +234,55 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 }
 
 type Queries struct {
-	db                   DBTX
-	tx                   *sql.Tx
-	friendsStmt          *sql.Stmt
-	friendsDeleteStmt    *sql.Stmt
-	friendsInsertStmt    *sql.Stmt
-	listsStmt            *sql.Stmt
-	listsDeleteStmt      *sql.Stmt
-	listsInsertStmt      *sql.Stmt
-	listsUpdateStmt      *sql.Stmt
-	messageSaveStmt      *sql.Stmt
-	messagesStmt         *sql.Stmt
-	playerStmt           *sql.Stmt
-	playerInsertStmt     *sql.Stmt
-	playerSearchStmt     *sql.Stmt
-	playerUpdateStmt     *sql.Stmt
-	sourcebansStmt       *sql.Stmt
-	sourcebansDeleteStmt *sql.Stmt
-	sourcebansInsertStmt *sql.Stmt
-	userNameSaveStmt     *sql.Stmt
-	userNamesStmt        *sql.Stmt
+	db                     DBTX
+	tx                     *sql.Tx
+	friendsStmt            *sql.Stmt
+	friendsDeleteStmt      *sql.Stmt
+	friendsInsertStmt      *sql.Stmt
+	listsStmt              *sql.Stmt
+	listsDeleteStmt        *sql.Stmt
+	listsInsertStmt        *sql.Stmt
+	listsUpdateStmt        *sql.Stmt
+	messageSaveStmt        *sql.Stmt
+	messagesStmt           *sql.Stmt
+	messagesAllStmt        *sql.Stmt
+	playerStmt             *sql.Stmt
+	playerInsertStmt       *sql.Stmt
+	playerSearchStmt       *sql.Stmt
+	playerUpdateStmt       *sql.Stmt
+	playersWhitelistedStmt *sql.Stmt
+	sourcebansStmt         *sql.Stmt
+	sourcebansDeleteStmt   *sql.Stmt
+	sourcebansInsertStmt   *sql.Stmt
+	userNameSaveStmt       *sql.Stmt
+	userNamesStmt          *sql.Stmt
+	userNamesAllStmt       *sql.Stmt
 }
 
 func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
-		db:                   tx,
-		tx:                   tx,
-		friendsStmt:          q.friendsStmt,
-		friendsDeleteStmt:    q.friendsDeleteStmt,
-		friendsInsertStmt:    q.friendsInsertStmt,
-		listsStmt:            q.listsStmt,
-		listsDeleteStmt:      q.listsDeleteStmt,
-		listsInsertStmt:      q.listsInsertStmt,
-		listsUpdateStmt:      q.listsUpdateStmt,
-		messageSaveStmt:      q.messageSaveStmt,
-		messagesStmt:         q.messagesStmt,
-		playerStmt:           q.playerStmt,
-		playerInsertStmt:     q.playerInsertStmt,
-		playerSearchStmt:     q.playerSearchStmt,
-		playerUpdateStmt:     q.playerUpdateStmt,
-		sourcebansStmt:       q.sourcebansStmt,
-		sourcebansDeleteStmt: q.sourcebansDeleteStmt,
-		sourcebansInsertStmt: q.sourcebansInsertStmt,
-		userNameSaveStmt:     q.userNameSaveStmt,
-		userNamesStmt:        q.userNamesStmt,
+		db:                     tx,
+		tx:                     tx,
+		friendsStmt:            q.friendsStmt,
+		friendsDeleteStmt:      q.friendsDeleteStmt,
+		friendsInsertStmt:      q.friendsInsertStmt,
+		listsStmt:              q.listsStmt,
+		listsDeleteStmt:        q.listsDeleteStmt,
+		listsInsertStmt:        q.listsInsertStmt,
+		listsUpdateStmt:        q.listsUpdateStmt,
+		messageSaveStmt:        q.messageSaveStmt,
+		messagesStmt:           q.messagesStmt,
+		messagesAllStmt:        q.messagesAllStmt,
+		playerStmt:             q.playerStmt,
+		playerInsertStmt:       q.playerInsertStmt,
+		playerSearchStmt:       q.playerSearchStmt,
+		playerUpdateStmt:       q.playerUpdateStmt,
+		playersWhitelistedStmt: q.playersWhitelistedStmt,
+		sourcebansStmt:         q.sourcebansStmt,
+		sourcebansDeleteStmt:   q.sourcebansDeleteStmt,
+		sourcebansInsertStmt:   q.sourcebansInsertStmt,
+		userNameSaveStmt:       q.userNameSaveStmt,
+		userNamesStmt:          q.userNamesStmt,
+		userNamesAllStmt:       q.userNamesAllStmt,
 	}
 }
@@ -18,15 +18,18 @@ type Querier interface {
 	ListsUpdate(ctx context.Context, arg ListsUpdateParams) error
 	MessageSave(ctx context.Context, arg MessageSaveParams) error
 	Messages(ctx context.Context, steamID int64) ([]PlayerMessage, error)
+	MessagesAll(ctx context.Context) ([]PlayerMessage, error)
 	Player(ctx context.Context, steamID int64) (PlayerRow, error)
 	PlayerInsert(ctx context.Context, arg PlayerInsertParams) (Player, error)
 	PlayerSearch(ctx context.Context, arg PlayerSearchParams) ([]PlayerSearchRow, error)
 	PlayerUpdate(ctx context.Context, arg PlayerUpdateParams) error
+	PlayersWhitelisted(ctx context.Context) ([]int64, error)
 	Sourcebans(ctx context.Context, steamID int64) ([]PlayerSourceban, error)
 	SourcebansDelete(ctx context.Context, steamID int64) error
 	SourcebansInsert(ctx context.Context, arg SourcebansInsertParams) (PlayerSourceban, error)
 	UserNameSave(ctx context.Context, arg UserNameSaveParams) error
 	UserNames(ctx context.Context, steamID int64) ([]PlayerName, error)
+	UserNamesAll(ctx context.Context) ([]PlayerName, error)
 }
 
 var _ Querier = (*Queries)(nil)
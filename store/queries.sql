@@ -73,6 +73,11 @@ WHERE (@steam_id = 0 OR p.steam_id = @steam_id)
 ORDER BY p.updated_on DESC
 LIMIT 1000;
 
+-- name: PlayersWhitelisted :many
+SELECT steam_id
+FROM player
+WHERE whitelist = true;
+
 -- name: UserNameSave :exec
 INSERT INTO player_names (name_id, steam_id, name, created_on)
 VALUES (?, ?, ?, ?);
@@ -82,6 +87,10 @@ SELECT name_id, steam_id, name, created_on
 FROM player_names
 WHERE steam_id = @steam_id;
 
+-- name: UserNamesAll :many
+SELECT name_id, steam_id, name, created_on
+FROM player_names;
+
 -- name: MessageSave :exec
 INSERT INTO player_messages (steam_id, message, team, dead, created_on)
 VALUES (?, ?, ?, ?, ?);
@@ -91,6 +100,10 @@ SELECT message_id, steam_id, message, team, dead, created_on
 FROM player_messages
 WHERE steam_id = @steam_id;
 
+-- name: MessagesAll :many
+SELECT message_id, steam_id, message, team, dead, created_on
+FROM player_messages;
+
 -- name: Friends :many
 SELECT steam_id, steam_id_friend, friend_since, created_on
 FROM player_friends
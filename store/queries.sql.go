@@ -243,6 +243,41 @@ func (q *Queries) Messages(ctx context.Context, steamID int64) ([]PlayerMessage,
 	return items, nil
 }
 
+const messagesAll = `-- name: MessagesAll :many
+SELECT message_id, steam_id, message, team, dead, created_on
+FROM player_messages
+`
+
+func (q *Queries) MessagesAll(ctx context.Context) ([]PlayerMessage, error) {
+	rows, err := q.query(ctx, q.messagesAllStmt, messagesAll)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PlayerMessage
+	for rows.Next() {
+		var i PlayerMessage
+		if err := rows.Scan(
+			&i.MessageID,
+			&i.SteamID,
+			&i.Message,
+			&i.Team,
+			&i.Dead,
+			&i.CreatedOn,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const player = `-- name: Player :one
 SELECT p.steam_id,
        p.visibility,
@@ -546,6 +581,35 @@ func (q *Queries) PlayerUpdate(ctx context.Context, arg PlayerUpdateParams) erro
 	return err
 }
 
+const playersWhitelisted = `-- name: PlayersWhitelisted :many
+SELECT steam_id
+FROM player
+WHERE whitelist = true
+`
+
+func (q *Queries) PlayersWhitelisted(ctx context.Context) ([]int64, error) {
+	rows, err := q.query(ctx, q.playersWhitelistedStmt, playersWhitelisted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var steam_id int64
+		if err := rows.Scan(&steam_id); err != nil {
+			return nil, err
+		}
+		items = append(items, steam_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const sourcebans = `-- name: Sourcebans :many
 SELECT sourcebans_id, steam_id, site,  player_name, reason, duration, permanent, created_on
 FROM player_sourcebans
@@ -688,3 +752,36 @@ func (q *Queries) UserNames(ctx context.Context, steamID int64) ([]PlayerName, e
 	}
 	return items, nil
 }
+
+const userNamesAll = `-- name: UserNamesAll :many
+SELECT name_id, steam_id, name, created_on
+FROM player_names
+`
+
+func (q *Queries) UserNamesAll(ctx context.Context) ([]PlayerName, error) {
+	rows, err := q.query(ctx, q.userNamesAllStmt, userNamesAll)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PlayerName
+	for rows.Next() {
+		var i PlayerName
+		if err := rows.Scan(
+			&i.NameID,
+			&i.SteamID,
+			&i.Name,
+			&i.CreatedOn,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
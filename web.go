@@ -7,6 +7,7 @@
 	"log/slog"
 	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/leighmacdonald/bd/frontend"
@@ -66,22 +67,28 @@ func responseOK(w http.ResponseWriter, status int, data any) {
 
 // createHandlers configures the routes. If the `release` tag is enabled, serves files from the embedded assets
 // in the binary.
-func createHandlers(store store.Querier, state *gameState, process *processState, settings *settingsManager, re *rules.Engine, rcon rconConnection) (*http.ServeMux, error) {
+func createHandlers(store store.Querier, state *gameState, process *processState, settings *settingsManager, re *rules.Engine, rcon rconConnection, lists listManager, local *localLists) (*http.ServeMux, error) {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("GET /api/state", onGetState(state, process))
 	mux.HandleFunc("GET /api/messages/{steam_id}", onGetMessages(store))
 	mux.HandleFunc("GET /api/names/{steam_id}", onGetNames(store))
-	mux.HandleFunc("POST /api/mark/{steam_id}", onMarkPlayerPost(settings, store, state, re))
-	mux.HandleFunc("DELETE /api/mark/{steam_id}", onDeleteMarkedPlayer(store, state, re))
+	mux.HandleFunc("POST /api/mark/{steam_id}", onMarkPlayerPost(settings, store, state, re, local))
+	mux.HandleFunc("DELETE /api/mark/{steam_id}", onDeleteMarkedPlayer(store, state, re, local))
 	mux.HandleFunc("GET /api/settings", onGetSettings(settings, re))
-	mux.HandleFunc("PUT /api/settings", onPutSettings(settings))
+	mux.HandleFunc("PUT /api/settings", onPutSettings(settings, lists, re))
 	mux.HandleFunc("GET /api/launch", onGGetLaunchGame(process, settings))
 	mux.HandleFunc("GET /api/quit", onGetQuitGame(process))
 	mux.HandleFunc("POST /api/whitelist/{steam_id}", onUpdateWhitelistPlayer(store, state, true))
 	mux.HandleFunc("DELETE /api/whitelist/{steam_id}", onUpdateWhitelistPlayer(store, state, false))
 	mux.HandleFunc("POST /api/notes/{steam_id}", onPostNotes(store, state))
 	mux.HandleFunc("POST /api/callvote/{steam_id}/{reason}", onCallVote(state, rcon))
+	mux.HandleFunc("GET /api/rules", onGetRules(re))
+	mux.HandleFunc("POST /api/rules", onPostRule(re, local))
+	mux.HandleFunc("PUT /api/rules/{index}", onPutRule(re, local))
+	mux.HandleFunc("DELETE /api/rules/{index}", onDeleteRule(re, local))
+	mux.HandleFunc("POST /api/rules/test", onPostRuleTest(store, re))
+	mux.HandleFunc("GET /api/lists/report", onGetListReport(store, re))
 
 	if settings.Settings().RunMode == ModeTest {
 		// Don't rely on assets when testing api endpoints
@@ -95,6 +102,20 @@ func createHandlers(store store.Querier, state *gameState, process *processState
 	return mux, nil
 }
 
+func ruleIndexParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	indexValue := r.PathValue("index")
+
+	index, errIndex := strconv.Atoi(indexValue)
+	if errIndex != nil {
+		responseErr(w, http.StatusBadRequest, nil)
+		slog.Error("Failed to parse rule index param", slog.String("index", indexValue))
+
+		return 0, false
+	}
+
+	return index, true
+}
+
 func steamIDParam(w http.ResponseWriter, r *http.Request) (steamid.SteamID, bool) {
 	sidValue := r.PathValue("steam_id")
 	steamID := steamid.New(sidValue)
@@ -5,9 +5,11 @@
 	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/leighmacdonald/bd/rules"
 	"github.com/leighmacdonald/bd/store"
+	"github.com/leighmacdonald/steamid/v4/steamid"
 )
 
 func onGetMessages(store store.Querier) http.HandlerFunc {
@@ -131,7 +133,7 @@ func onGetSettings(settings *settingsManager, rules *rules.Engine) http.HandlerF
 	}
 }
 
-func onPutSettings(settings *settingsManager) http.HandlerFunc {
+func onPutSettings(settings *settingsManager, lists listManager, re *rules.Engine) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var wus WebUserSettings
 		if !bind(w, r, &wus) {
@@ -148,6 +150,13 @@ func onPutSettings(settings *settingsManager) http.HandlerFunc {
 			return
 		}
 
+		if errTaxonomy := re.SetTaxonomy(wus.taxonomyAttributes()); errTaxonomy != nil {
+			responseErr(w, http.StatusBadRequest, errTaxonomy)
+			return
+		}
+
+		lists.notifyListsChanged()
+
 		responseOK(w, http.StatusOK, settings.Settings())
 	}
 }
@@ -234,20 +243,22 @@ func onPostNotes(db store.Querier, state *gameState) http.HandlerFunc {
 
 type PostMarkPlayerOpts struct {
 	Attrs []string `json:"attrs"`
+	// Expires optionally sets when the mark expires, overriding the default attribute ttls
+	Expires time.Time `json:"expires"`
 }
 
 type UnmarkResponse struct {
 	Remaining int `json:"remaining"`
 }
 
-func onDeleteMarkedPlayer(db store.Querier, state *gameState, re *rules.Engine) http.HandlerFunc {
+func onDeleteMarkedPlayer(db store.Querier, state *gameState, re *rules.Engine, local *localLists) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		sid, sidOk := steamIDParam(w, r)
 		if !sidOk {
 			return
 		}
 
-		remaining, errUnmark := unMark(r.Context(), re, db, state, sid)
+		remaining, errUnmark := unMark(r.Context(), re, db, local, sid)
 		if errUnmark != nil {
 			if errors.Is(errUnmark, errNotMarked) {
 				responseOK(w, http.StatusNotFound, nil)
@@ -264,7 +275,7 @@ func onDeleteMarkedPlayer(db store.Querier, state *gameState, re *rules.Engine)
 	}
 }
 
-func onMarkPlayerPost(sm *settingsManager, db store.Querier, state *gameState, re *rules.Engine) http.HandlerFunc {
+func onMarkPlayerPost(sm *settingsManager, db store.Querier, state *gameState, re *rules.Engine, local *localLists) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		sid, sidOk := steamIDParam(w, r)
 		if !sidOk {
@@ -283,7 +294,7 @@ func onMarkPlayerPost(sm *settingsManager, db store.Querier, state *gameState, r
 			return
 		}
 
-		if errCreateMark := mark(r.Context(), sm, db, state, re, sid, opts.Attrs); errCreateMark != nil {
+		if errCreateMark := mark(r.Context(), sm, db, state, re, local, sid, opts.Attrs, opts.Expires); errCreateMark != nil {
 			if errors.Is(errCreateMark, rules.ErrDuplicateSteamID) {
 				responseErr(w, http.StatusConflict, nil)
 				slog.Warn("Tried to mark duplicate steam id", slog.String("steam_id", sid.String()))
@@ -318,3 +329,177 @@ func onUpdateWhitelistPlayer(db store.Querier, state *gameState, enable bool) ht
 		responseOK(w, http.StatusNoContent, nil)
 	}
 }
+
+type ruleIndexResponse struct {
+	Index int `json:"index"`
+}
+
+// responseRuleErr responds to a failed change to the local rules. Invalid rules are reported back to the user.
+func responseRuleErr(w http.ResponseWriter, errRule error) {
+	if errors.Is(errRule, rules.ErrUnknownRule) {
+		responseErr(w, http.StatusNotFound, nil)
+
+		return
+	}
+
+	responseErr(w, http.StatusBadRequest, errRule.Error())
+}
+
+func onGetRules(re *rules.Engine) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		responseOK(w, http.StatusOK, re.Rules())
+	}
+}
+
+func onPostRule(re *rules.Engine, local *localLists) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var rule rules.RuleDefinition
+		if !bind(w, r, &rule) {
+			return
+		}
+
+		index, errAdd := re.AddRule(rule)
+		if errAdd != nil {
+			responseRuleErr(w, errAdd)
+
+			return
+		}
+
+		local.saveRules()
+
+		responseOK(w, http.StatusCreated, ruleIndexResponse{Index: index})
+	}
+}
+
+func onPutRule(re *rules.Engine, local *localLists) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		index, indexOk := ruleIndexParam(w, r)
+		if !indexOk {
+			return
+		}
+
+		var rule rules.RuleDefinition
+		if !bind(w, r, &rule) {
+			return
+		}
+
+		if errUpdate := re.UpdateRule(index, rule); errUpdate != nil {
+			responseRuleErr(w, errUpdate)
+
+			return
+		}
+
+		local.saveRules()
+
+		responseOK(w, http.StatusNoContent, nil)
+	}
+}
+
+func onDeleteRule(re *rules.Engine, local *localLists) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		index, indexOk := ruleIndexParam(w, r)
+		if !indexOk {
+			return
+		}
+
+		if errDelete := re.DeleteRule(index); errDelete != nil {
+			responseRuleErr(w, errDelete)
+
+			return
+		}
+
+		local.saveRules()
+
+		responseOK(w, http.StatusNoContent, nil)
+	}
+}
+
+type ruleTestRequest struct {
+	Rule rules.RuleDefinition `json:"rule"`
+	rules.RuleTestSamples
+	// SteamIDs are evaluated as players using their stored names, messages and avatar
+	SteamIDs []steamid.SteamID `json:"steam_ids"`
+	// Replay the rule over the full name and message history
+	Replay bool `json:"replay"`
+}
+
+type ruleTestResponse struct {
+	Result rules.RuleTestResult    `json:"result"`
+	Replay *rules.RuleReplayResult `json:"replay"`
+}
+
+func onPostRuleTest(db store.Querier, re *rules.Engine) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req ruleTestRequest
+		if !bind(w, r, &req) {
+			return
+		}
+
+		for _, steamID := range req.SteamIDs {
+			if !steamID.Valid() {
+				responseErr(w, http.StatusBadRequest, nil)
+
+				return
+			}
+
+			player, errPlayer := ruleTestPlayer(r.Context(), db, steamID)
+			if errPlayer != nil {
+				responseErr(w, http.StatusInternalServerError, nil)
+				slog.Error("Failed to load rule test player", errAttr(errPlayer), slog.String("steam_id", steamID.String()))
+
+				return
+			}
+
+			req.Players = append(req.Players, player)
+		}
+
+		result, errTest := re.TestRule(req.Rule, req.RuleTestSamples)
+		if errTest != nil {
+			responseErr(w, http.StatusBadRequest, errTest.Error())
+
+			return
+		}
+
+		resp := ruleTestResponse{Result: result}
+
+		if req.Replay {
+			players, errPlayers := ruleReplayPlayers(r.Context(), db)
+			if errPlayers != nil {
+				responseErr(w, http.StatusInternalServerError, nil)
+				slog.Error("Failed to load rule replay players", errAttr(errPlayers))
+
+				return
+			}
+
+			replay, errReplay := re.ReplayRule(req.Rule, players)
+			if errReplay != nil {
+				responseErr(w, http.StatusBadRequest, errReplay.Error())
+
+				return
+			}
+
+			resp.Replay = &replay
+		}
+
+		responseOK(w, http.StatusOK, resp)
+	}
+}
+
+func onGetListReport(db store.Querier, re *rules.Engine) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		whitelisted, errWhitelist := db.PlayersWhitelisted(r.Context())
+		if errWhitelist != nil {
+			responseErr(w, http.StatusInternalServerError, nil)
+			slog.Error("Failed to load whitelisted players", errAttr(errWhitelist))
+
+			return
+		}
+
+		whitelist := make(steamid.Collection, len(whitelisted))
+		for idx, sid64 := range whitelisted {
+			whitelist[idx] = steamid.New(sid64)
+		}
+
+		responseOK(w, http.StatusOK, re.ListReport(whitelist))
+	}
+}
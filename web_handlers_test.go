@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/leighmacdonald/bd/platform"
+	"github.com/leighmacdonald/bd/rules"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSettingsManager creates a settings manager which stores its config and lists in a temporary directory.
+func newTestSettingsManager(t *testing.T) *settingsManager {
+	t.Helper()
+
+	settingsMgr := newSettingsManager(platform.New())
+	settingsMgr.configRoot = t.TempDir()
+
+	require.NoError(t, settingsMgr.setup())
+
+	return settingsMgr
+}
+
+// serveJSON sends the request, with the body encoded as json, to the handler and returns the response.
+func serveJSON(t *testing.T, handler http.Handler, method string, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&payload).Encode(body))
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(method, path, &payload))
+
+	return recorder
+}
+
+func TestRuleHandlers(t *testing.T) {
+	var (
+		engine = rules.New()
+		local  = newLocalLists(engine, newTestSettingsManager(t))
+		mux    = http.NewServeMux()
+		rule   = rules.RuleDefinition{
+			Description: "bot names",
+			Triggers: rules.RuleTriggers{
+				UsernameTextMatch: &rules.RuleTriggerNameMatch{Mode: rules.TextMatchModeContains, Patterns: []string{"bot"}},
+			},
+		}
+	)
+
+	mux.HandleFunc("GET /api/rules", onGetRules(engine))
+	mux.HandleFunc("POST /api/rules", onPostRule(engine, local))
+	mux.HandleFunc("PUT /api/rules/{index}", onPutRule(engine, local))
+	mux.HandleFunc("DELETE /api/rules/{index}", onDeleteRule(engine, local))
+
+	created := serveJSON(t, mux, http.MethodPost, "/api/rules", rule)
+	require.Equal(t, http.StatusCreated, created.Code)
+
+	var index ruleIndexResponse
+	require.NoError(t, json.NewDecoder(created.Body).Decode(&index))
+	require.Equal(t, 0, index.Index)
+	require.NotNil(t, engine.MatchName("a bot"))
+
+	require.Equal(t, http.StatusBadRequest, serveJSON(t, mux, http.MethodPost, "/api/rules", rules.RuleDefinition{}).Code)
+
+	rule.Triggers.UsernameTextMatch.Patterns = []string{"cheater"}
+	require.Equal(t, http.StatusNoContent, serveJSON(t, mux, http.MethodPut, "/api/rules/0", rule).Code)
+	require.Equal(t, http.StatusNotFound, serveJSON(t, mux, http.MethodPut, "/api/rules/5", rule).Code)
+	require.Equal(t, http.StatusBadRequest, serveJSON(t, mux, http.MethodPut, "/api/rules/first", rule).Code)
+	require.Nil(t, engine.MatchName("a bot"))
+	require.NotNil(t, engine.MatchName("a cheater"))
+
+	var listed []rules.RuleDefinition
+
+	require.NoError(t, json.NewDecoder(serveJSON(t, mux, http.MethodGet, "/api/rules", nil).Body).Decode(&listed))
+	require.Len(t, listed, 1)
+	require.Equal(t, "bot names", listed[0].Description)
+
+	require.Equal(t, http.StatusNoContent, serveJSON(t, mux, http.MethodDelete, "/api/rules/0", nil).Code)
+	require.Equal(t, http.StatusNotFound, serveJSON(t, mux, http.MethodDelete, "/api/rules/0", nil).Code)
+	require.Empty(t, engine.Rules())
+	require.True(t, local.rules, "Rule changes should be saved")
+}
+
+func TestRuleTestHandler(t *testing.T) {
+	var (
+		engine = rules.New()
+		mux    = http.NewServeMux()
+		req    = ruleTestRequest{
+			Rule: rules.RuleDefinition{
+				Description: "bot names",
+				Actions:     rules.RuleActions{Mark: []string{"bots"}},
+				Triggers: rules.RuleTriggers{
+					UsernameTextMatch: &rules.RuleTriggerNameMatch{Mode: rules.TextMatchModeContains, Patterns: []string{"bot"}},
+				},
+			},
+			RuleTestSamples: rules.RuleTestSamples{Names: []string{"a bot", "Uncle Dane"}},
+		}
+	)
+
+	mux.HandleFunc("POST /api/rules/test", onPostRuleTest(nil, engine))
+
+	recorder := serveJSON(t, mux, http.MethodPost, "/api/rules/test", req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var resp ruleTestResponse
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&resp))
+	require.Len(t, resp.Result.Names, 2)
+	require.True(t, resp.Result.Names[0].Matched)
+	require.Equal(t, []string{"bot"}, resp.Result.Names[0].Matches[0].Mark, "Marks should use the canonical attribute")
+	require.False(t, resp.Result.Names[1].Matched)
+	require.Nil(t, resp.Replay)
+	require.Empty(t, engine.Rules(), "Tested rules should not be registered")
+
+	require.Equal(t, http.StatusBadRequest,
+		serveJSON(t, mux, http.MethodPost, "/api/rules/test", ruleTestRequest{}).Code)
+}